@@ -17,6 +17,17 @@ import (
 //
 // INDEXES ARE USED INSTEAD OF POINTERS IN ALL STRUCTURES AS THESE STRUCTURES WILL
 // BE SENT OVER A NETWORK AS THEIR PRIMARY USE
+//
+// Surfaces may have a boundary. A boundary edge has its face as FaceA and
+// NoFace as FaceB, with NoEdge for PrevB and NextB. The edges of a vertex on
+// the boundary form an open fan rather than a loop, see WingedVertex.
+
+const (
+	// the missing face on the outside of a boundary edge
+	NoFace int32 = -1
+	// the missing prev and next edges on the outside of a boundary edge
+	NoEdge int32 = -1
+)
 
 // represents a face of a tiled surface
 type WingedFace struct {
@@ -37,8 +48,10 @@ type WingedEdge struct {
 
 // represents a vertex point, currently as an embedding in 3-space
 type WingedVertex struct {
-	Coords          [3]float64 // x, y, z
-	Edges           []int32    // in clockwise order, indexed from Grid
+	Coords [3]float64 // x, y, z
+	// in clockwise order, indexed from Grid. On the boundary the first edge
+	// has no previous edge for the vertex and the last has no next edge.
+	Edges           []int32
 	vertexNeighbors []int32
 }
 
@@ -49,52 +62,59 @@ type WingedGrid struct {
 	Faces    []WingedFace
 	Edges    []WingedEdge
 	Vertices []WingedVertex
+	// for a grid wrapped around a flat map, the distance along each axis
+	// after which vertex coords repeat, 0 for an axis that doesn't wrap. Not
+	// kept by the encodings or by grids built from this one.
+	Period [3]float64
 }
 
 /******************* Winged Face ********************/
-// returns faces adjacent to this face
+// returns faces adjacent to this face, in clockwise order, leaving out the
+// missing faces across boundary edges
 // does not check bounds
 func (theGrid WingedGrid) NeighborsForFace(faceIndex int32) ([]int32, error) {
 	var err error
 	var theFace WingedFace
 	theFace = theGrid.Faces[faceIndex]
-	// one neighbor for each face
-	var neighbors []int32 = make([]int32, len(theFace.Edges))
-	for index, edgeIndex := range theFace.Edges {
+	// one neighbor for each edge, unless on the boundary
+	var neighbors []int32 = make([]int32, 0, len(theFace.Edges))
+	for _, edgeIndex := range theFace.Edges {
 		var theEdge WingedEdge
 		theEdge = theGrid.Edges[edgeIndex]
 		var neighborIndex int32
-		neighborIndex, err = theEdge.AdjacentForFace(faceIndex)
+		var edgeErr error
+		neighborIndex, edgeErr = theEdge.AdjacentForFace(faceIndex)
 		// don't check error, pass the last one on to the next function
-		neighbors[index] = neighborIndex
+		if edgeErr != nil {
+			err = edgeErr
+		}
+		if neighborIndex != NoFace {
+			neighbors = append(neighbors, neighborIndex)
+		}
 	}
 
 	return neighbors, err
 }
 
+// returns the mean of the face's corners, taken across the seams of a wrapped
+// grid and moved back within one period like the vertex coords
 func (theGrid WingedGrid) FaceCenter(faceIndex int32) ([3]float64, error) {
-	var startFace WingedFace = theGrid.Faces[faceIndex]
 	var faceCenter [3]float64
-	var count float64
-	var err error
-	for _, edgeIndex := range startFace.Edges {
-		var vertex WingedVertex
-		var vertexIndex int32
-		vertexIndex, err = theGrid.Edges[edgeIndex].FirstVertexForFace(faceIndex)
-		if err != nil {
-			return faceCenter, err
-		}
-		vertex = theGrid.Vertices[vertexIndex]
-		faceCenter[0] = faceCenter[0] + vertex.Coords[0]
-		faceCenter[1] = faceCenter[1] + vertex.Coords[1]
-		faceCenter[2] = faceCenter[2] + vertex.Coords[2]
-		count = count + 1
+	coords, err := theGrid.FaceCoords(faceIndex)
+	if err != nil {
+		return faceCenter, err
 	}
+	for _, corner := range coords {
+		faceCenter[0] = faceCenter[0] + corner[0]
+		faceCenter[1] = faceCenter[1] + corner[1]
+		faceCenter[2] = faceCenter[2] + corner[2]
+	}
+	var count float64 = float64(len(coords))
 	faceCenter[0] = faceCenter[0] / count
 	faceCenter[1] = faceCenter[1] / count
 	faceCenter[2] = faceCenter[2] / count
 
-	return faceCenter, nil
+	return theGrid.wrapCoords(faceCenter), nil
 }
 
 /******************* Winged Edge ********************/
@@ -103,7 +123,7 @@ func (theGrid WingedGrid) FaceCenter(faceIndex int32) ([3]float64, error) {
 func (theEdge WingedEdge) NextEdgeForFace(faceIndex int32) (int32, error) {
 	if theEdge.FaceA == faceIndex {
 		return theEdge.NextA, nil
-	} else if theEdge.FaceB == faceIndex {
+	} else if theEdge.FaceB == faceIndex && faceIndex != NoFace {
 		return theEdge.NextB, nil
 	}
 	return -1, errors.New("Edge not associated with face.")
@@ -114,7 +134,7 @@ func (theEdge WingedEdge) NextEdgeForFace(faceIndex int32) (int32, error) {
 func (theEdge WingedEdge) PrevEdgeForFace(faceIndex int32) (int32, error) {
 	if theEdge.FaceA == faceIndex {
 		return theEdge.PrevA, nil
-	} else if theEdge.FaceB == faceIndex {
+	} else if theEdge.FaceB == faceIndex && faceIndex != NoFace {
 		return theEdge.PrevB, nil
 	}
 	return -1, errors.New("Edge not associated with face.")
@@ -125,7 +145,7 @@ func (theEdge WingedEdge) PrevEdgeForFace(faceIndex int32) (int32, error) {
 func (theEdge WingedEdge) FirstVertexForFace(faceIndex int32) (int32, error) {
 	if theEdge.FaceA == faceIndex {
 		return theEdge.FirstVertexA, nil
-	} else if theEdge.FaceB == faceIndex {
+	} else if theEdge.FaceB == faceIndex && faceIndex != NoFace {
 		return theEdge.FirstVertexB, nil
 	}
 	return -1, errors.New("Edge not associated with face.")
@@ -136,36 +156,55 @@ func (theEdge WingedEdge) FirstVertexForFace(faceIndex int32) (int32, error) {
 func (theEdge WingedEdge) SecondVertexForFace(faceIndex int32) (int32, error) {
 	if theEdge.FaceA == faceIndex {
 		return theEdge.FirstVertexB, nil
-	} else if theEdge.FaceB == faceIndex {
+	} else if theEdge.FaceB == faceIndex && faceIndex != NoFace {
 		return theEdge.FirstVertexA, nil
 	}
 	return -1, errors.New("Edge not associated with face.")
 }
 
+// Returns the index of the next clockwise edge around the given vertex,
+// NoEdge if the face between them is missing, or an error if the edge is not
+// associated with the vertex.
 func (theEdge WingedEdge) NextEdgeForVertex(vertexIndex int32) (int32, error) {
 	if theEdge.FirstVertexA == vertexIndex {
-		return theEdge.PrevEdgeForFace(theEdge.FaceA)
+		return edgeForSide(theEdge.FaceA, theEdge.PrevA), nil
 	} else if theEdge.FirstVertexB == vertexIndex {
-		return theEdge.PrevEdgeForFace(theEdge.FaceB)
+		return edgeForSide(theEdge.FaceB, theEdge.PrevB), nil
 	}
 	return -1, errors.New("Edge not associated with Vertex.")
 }
 
+// Returns the index of the previous clockwise edge around the given vertex,
+// NoEdge if the face between them is missing, or an error if the edge is not
+// associated with the vertex.
 func (theEdge WingedEdge) PrevEdgeForVertex(vertexIndex int32) (int32, error) {
 	if theEdge.FirstVertexA == vertexIndex {
-		return theEdge.NextEdgeForFace(theEdge.FaceB)
+		return edgeForSide(theEdge.FaceB, theEdge.NextB), nil
 	} else if theEdge.FirstVertexB == vertexIndex {
-		return theEdge.NextEdgeForFace(theEdge.FaceA)
+		return edgeForSide(theEdge.FaceA, theEdge.NextA), nil
 	}
 	return -1, errors.New("Edge not associated with Vertex.")
 }
 
-// returns the other face associated with an edge, or an error
-// if the edge is not associated with the face.
+// the edge on one side of this edge, or NoEdge if that side has no face
+func edgeForSide(faceIndex, edgeIndex int32) int32 {
+	if faceIndex == NoFace {
+		return NoEdge
+	}
+	return edgeIndex
+}
+
+// Returns whether the edge has a missing face
+func (theEdge WingedEdge) IsBoundary() bool {
+	return theEdge.FaceA == NoFace || theEdge.FaceB == NoFace
+}
+
+// returns the other face associated with an edge, NoFace across a boundary
+// edge, or an error if the edge is not associated with the face.
 func (theEdge WingedEdge) AdjacentForFace(faceIndex int32) (int32, error) {
 	if theEdge.FaceA == faceIndex {
 		return theEdge.FaceB, nil
-	} else if theEdge.FaceB == faceIndex {
+	} else if theEdge.FaceB == faceIndex && faceIndex != NoFace {
 		return theEdge.FaceA, nil
 	}
 	return -1, errors.New("Edge not associated with face.")
@@ -0,0 +1,140 @@
+package wingedGrid
+
+import (
+	"errors"
+)
+
+// Catmull-Clark subdivision (Catmull and Clark 1978) of grids with faces of
+// any size, such as the hexagons and pentagons of a dual grid. Boundary edges
+// and vertices follow the cubic B-spline rules of the boundary curve.
+
+// options for WingedGrid.SubdivideCatmullClark
+type CatmullClarkOptions struct {
+	// move every vertex to the sphere through the original vertices, at their
+	// mean distance from the origin, after each level, failing if that leaves
+	// the grid invalid
+	Spherical bool
+}
+
+// Splits each face into quads, one per corner, levels times, and smooths the
+// result with the Catmull-Clark rules. Every face after the first level is a
+// quad.
+//
+// At each level the old vertices keep their index, followed by one vertex per
+// old edge and then one at the center of each old face. Old face f, with
+// vertices v0...vn-1 and new vertices mk on the edge from vk, is replaced by
+// the quads (vk, mk, center, mk-1) in order, starting at the sum of the sizes
+// of the faces before it.
+func (oldGrid WingedGrid) SubdivideCatmullClark(levels int32, options CatmullClarkOptions) (WingedGrid, error) {
+	if levels < 1 {
+		return WingedGrid{}, errors.New("Catmull-Clark subdivision needs at least one level")
+	}
+	var radius float64
+	if options.Spherical {
+		radius = oldGrid.meanRadius()
+	}
+
+	var grid WingedGrid = oldGrid
+	var err error
+	for level := int32(0); level < levels; level++ {
+		grid, err = grid.catmullClarkLevel()
+		if err != nil {
+			return WingedGrid{}, err
+		}
+		if options.Spherical {
+			err = grid.projectToSphere(radius)
+			if err != nil {
+				return WingedGrid{}, err
+			}
+		}
+	}
+	return grid, nil
+}
+
+// Returns the grid after one level of Catmull-Clark subdivision
+func (oldGrid WingedGrid) catmullClarkLevel() (WingedGrid, error) {
+	faceVertices, err := oldGrid.faceVertexLists()
+	if err != nil {
+		return WingedGrid{}, err
+	}
+	var quadCount int
+	for _, vertices := range faceVertices {
+		quadCount += len(vertices)
+	}
+	var vertexCount int = len(oldGrid.Vertices) + len(oldGrid.Edges) + len(oldGrid.Faces)
+	if vertexCount > maxGridElements || quadCount > maxGridElements {
+		return WingedGrid{}, errors.New("Too many elements for int32 indices")
+	}
+	var edgeOffset int = len(oldGrid.Vertices)
+	var faceOffset int = edgeOffset + len(oldGrid.Edges)
+	var coords [][3]float64 = make([][3]float64, vertexCount)
+
+	// face points at the centers
+	for faceIndex, vertices := range faceVertices {
+		var center [3]float64
+		for _, vertexIndex := range vertices {
+			center = vectorAdd(center, oldGrid.Vertices[vertexIndex].Coords)
+		}
+		coords[faceOffset+faceIndex] = vectorScale(center, 1/float64(len(vertices)))
+	}
+
+	// edge points from the ends and, away from the boundary, the face points
+	var midpoints [][3]float64 = make([][3]float64, len(oldGrid.Edges))
+	for edgeIndex, edge := range oldGrid.Edges {
+		var first, second [3]float64 = oldGrid.Vertices[edge.FirstVertexA].Coords, oldGrid.Vertices[edge.FirstVertexB].Coords
+		midpoints[edgeIndex] = vectorScale(vectorAdd(first, second), 0.5)
+		if edge.IsBoundary() {
+			coords[edgeOffset+edgeIndex] = midpoints[edgeIndex]
+			continue
+		}
+		var centers [3]float64 = vectorAdd(coords[faceOffset+int(edge.FaceA)], coords[faceOffset+int(edge.FaceB)])
+		coords[edgeOffset+edgeIndex] = vectorScale(vectorAdd(vectorAdd(first, second), centers), 0.25)
+	}
+
+	for vertexIndex, vertex := range oldGrid.Vertices {
+		coords[vertexIndex] = oldGrid.catmullClarkVertexPosition(int32(vertexIndex), vertex, coords[faceOffset:], midpoints)
+	}
+
+	var faces [][]int32 = make([][]int32, 0, quadCount)
+	for faceIndex, vertices := range faceVertices {
+		var edges []int32 = oldGrid.Faces[faceIndex].Edges
+		var center int32 = int32(faceOffset + faceIndex)
+		for k, vertexIndex := range vertices {
+			var prev int32 = edges[(k+len(edges)-1)%len(edges)]
+			faces = append(faces, []int32{vertexIndex, int32(edgeOffset) + edges[k], center, int32(edgeOffset) + prev})
+		}
+	}
+	return NewGridFromPolygons(coords, faces)
+}
+
+// Returns the new position of an old vertex, given the face points and the
+// old edge midpoints
+func (oldGrid WingedGrid) catmullClarkVertexPosition(vertexIndex int32, vertex WingedVertex, facePoints, midpoints [][3]float64) [3]float64 {
+	var faceSum, edgeSum, boundarySum [3]float64
+	var boundaryCount int
+	var faceCount float64
+	for _, edgeIndex := range vertex.Edges {
+		var edge WingedEdge = oldGrid.Edges[edgeIndex]
+		edgeSum = vectorAdd(edgeSum, midpoints[edgeIndex])
+		if edge.IsBoundary() {
+			boundarySum = vectorAdd(boundarySum, midpoints[edgeIndex])
+			boundaryCount++
+		}
+		// each face once, from the side where the edge starts here
+		var faceIndex int32 = edge.FaceB
+		if edge.FirstVertexA == vertexIndex {
+			faceIndex = edge.FaceA
+		}
+		if faceIndex != NoFace {
+			faceSum = vectorAdd(faceSum, facePoints[faceIndex])
+			faceCount++
+		}
+	}
+	if boundaryCount == 2 {
+		// 3/4 of the vertex and 1/8 of each boundary neighbor
+		return vectorAdd(vectorScale(vertex.Coords, 0.5), vectorScale(boundarySum, 0.25))
+	}
+	var n float64 = float64(len(vertex.Edges))
+	var average [3]float64 = vectorAdd(vectorScale(faceSum, 1/faceCount), vectorScale(edgeSum, 2/n))
+	return vectorScale(vectorAdd(average, vectorScale(vertex.Coords, n-3)), 1/n)
+}
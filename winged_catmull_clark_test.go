@@ -0,0 +1,130 @@
+package wingedGrid
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSubdivideCatmullClarkCube(t *testing.T) {
+	cube, _ := BaseCube()
+	grid, err := cube.SubdivideCatmullClark(1, CatmullClarkOptions{})
+	if err != nil {
+		t.Fatalf("Failed to subdivide: %s", err)
+	}
+	if len(grid.Vertices) != 26 || len(grid.Edges) != 48 || len(grid.Faces) != 24 {
+		t.Fatalf("Unexpected counts %d %d %d", len(grid.Vertices), len(grid.Edges), len(grid.Faces))
+	}
+	if report := grid.Validate(ValidateOptions{Spherical: true}); !report.Valid() {
+		t.Errorf("Subdivided cube invalid: %s", report.Err())
+	}
+	// a corner with three faces moves to 5/9 of the way
+	for index := 0; index < 8; index++ {
+		var expected [3]float64 = vectorScale(cube.Vertices[index].Coords, 5.0/9)
+		if distanceBetween3Points(grid.Vertices[index].Coords, expected) > 1e-12 {
+			t.Errorf("Corner %d at %v, expected %v", index, grid.Vertices[index].Coords, expected)
+		}
+	}
+	// face points stay at the face centers, which for a cube are on the axes
+	for faceIndex := range cube.Faces {
+		center, _ := cube.FaceCenter(int32(faceIndex))
+		if grid.Vertices[8+12+faceIndex].Coords != center {
+			t.Errorf("Face point %d at %v, expected %v", faceIndex, grid.Vertices[8+12+faceIndex].Coords, center)
+		}
+	}
+	// quads of face f start at its first corner
+	cubeVertices := mustFaceVertexLists(t, cube)
+	gridVertices := mustFaceVertexLists(t, grid)
+	for faceIndex, vertices := range cubeVertices {
+		for k, vertexIndex := range vertices {
+			var quad []int32 = gridVertices[4*faceIndex+k]
+			if quad[0] != vertexIndex || quad[2] != int32(8+12+faceIndex) {
+				t.Errorf("Quad %d is %v", 4*faceIndex+k, quad)
+			}
+		}
+	}
+}
+
+func TestSubdivideCatmullClarkHexPlanet(t *testing.T) {
+	base, _ := BaseIcosahedron()
+	subdivided, _ := base.SubdivideTriangles(2)
+	planet, err := subdivided.CreateDual()
+	if err != nil {
+		t.Fatalf("Failed to create dual: %s", err)
+	}
+	var radius float64
+	for _, vertex := range planet.Vertices {
+		radius += vectorLength(vertex.Coords)
+	}
+	radius = radius / float64(len(planet.Vertices))
+
+	grid, err := planet.SubdivideCatmullClark(2, CatmullClarkOptions{Spherical: true})
+	if err != nil {
+		t.Fatalf("Failed to subdivide: %s", err)
+	}
+	if report := grid.Validate(ValidateOptions{Spherical: true}); !report.Valid() {
+		t.Fatalf("Subdivided planet invalid: %s", report.Err())
+	}
+	if len(grid.Faces) != 4*2*len(planet.Edges) {
+		t.Errorf("Expected %d faces, got %d", 4*2*len(planet.Edges), len(grid.Faces))
+	}
+	for index, face := range grid.Faces {
+		if len(face.Edges) != 4 {
+			t.Errorf("Face %d has %d edges", index, len(face.Edges))
+		}
+	}
+	for index, vertex := range grid.Vertices {
+		if math.Abs(vectorLength(vertex.Coords)-radius) > 1e-9 {
+			t.Errorf("Vertex %d is off the sphere", index)
+		}
+	}
+}
+
+func TestSubdivideCatmullClarkBoundary(t *testing.T) {
+	hexes, err := PlanarHexHexagon(2)
+	if err != nil {
+		t.Fatalf("Failed to create map: %s", err)
+	}
+	grid, err := hexes.SubdivideCatmullClark(2, CatmullClarkOptions{})
+	if err != nil {
+		t.Fatalf("Failed to subdivide: %s", err)
+	}
+	if report := grid.Validate(ValidateOptions{}); !report.Valid() {
+		t.Fatalf("Subdivided map invalid: %s", report.Err())
+	}
+	if euler := len(grid.Vertices) - len(grid.Edges) + len(grid.Faces); euler != 1 {
+		t.Errorf("Expected Euler characteristic 1, got %d", euler)
+	}
+	var boundary, oldBoundary int
+	for _, edge := range hexes.Edges {
+		if edge.IsBoundary() {
+			oldBoundary++
+		}
+	}
+	for _, edge := range grid.Edges {
+		if edge.IsBoundary() {
+			boundary++
+		}
+	}
+	if boundary != 4*oldBoundary {
+		t.Errorf("Expected %d boundary edges, got %d", 4*oldBoundary, boundary)
+	}
+
+	if _, err := hexes.SubdivideCatmullClark(0, CatmullClarkOptions{}); err == nil {
+		t.Error("Expected an error for no levels")
+	}
+}
+
+func TestSubdivideCatmullClarkSphericalDegenerate(t *testing.T) {
+	// flat maps through the origin can't be moved to a sphere
+	hexMap, _ := PlanarHexMap(6, 4, NoWrap)
+	triangleMap, _ := PlanarTriangleMap(6, 4, NoWrap)
+	triangleHexagon, _ := PlanarTriangleHexagon(2)
+	for index, grid := range []WingedGrid{hexMap, triangleMap, triangleHexagon} {
+		if _, err := grid.SubdivideCatmullClark(1, CatmullClarkOptions{Spherical: true}); err == nil {
+			t.Errorf("Expected an error moving map %d to the sphere", index)
+		}
+		if _, err := grid.SubdivideCatmullClark(1, CatmullClarkOptions{}); err != nil {
+			t.Errorf("Failed to subdivide map %d: %s", index, err)
+		}
+	}
+}
@@ -0,0 +1,339 @@
+package wingedGrid
+
+import (
+	"fmt"
+)
+
+// Conway polyhedron operators (Conway, Burgiel and Goodman-Strauss 2008, with
+// the extensions of Hart), alongside CreateDual which is the operator d.
+//
+// Each operator builds a new grid from the faces around the old vertices,
+// edges and faces, so needs a closed grid. New vertices on an edge are placed
+// a third of the way along it, new vertices inside a face a third of the way
+// from a corner to the face center, and face centers at the mean of the
+// corners. The resulting faces are generally not planar unless moved to the
+// sphere.
+
+// options for the Conway operators
+type ConwayOptions struct {
+	// move every vertex to the sphere through the original vertices, at their
+	// mean distance from the origin, failing if that leaves the grid invalid
+	Spherical bool
+}
+
+// Conway's a: a vertex at the middle of each edge, with a face for each old
+// face and then for each old vertex, in index order. Vertex e is on edge e.
+func (oldGrid WingedGrid) Ambo(options ConwayOptions) (WingedGrid, error) {
+	faceVertices, err := oldGrid.conwayFaceVertices("ambo")
+	if err != nil {
+		return WingedGrid{}, err
+	}
+	var coords [][3]float64 = make([][3]float64, len(oldGrid.Edges))
+	for edgeIndex, edge := range oldGrid.Edges {
+		coords[edgeIndex] = oldGrid.edgePoint(edge, edge.FirstVertexA, 0.5)
+	}
+	var faces [][]int32 = make([][]int32, 0, len(oldGrid.Faces)+len(oldGrid.Vertices))
+	for faceIndex := range faceVertices {
+		faces = append(faces, append([]int32(nil), oldGrid.Faces[faceIndex].Edges...))
+	}
+	for _, vertex := range oldGrid.Vertices {
+		faces = append(faces, append([]int32(nil), vertex.Edges...))
+	}
+	return oldGrid.conwayResult(coords, faces, options)
+}
+
+// Conway's k: a pyramid on each face. The old vertices keep their index,
+// followed by the center of each face. Old face f with n corners becomes n
+// triangles, one per edge in order, starting at the sum of the sizes of the
+// faces before it.
+func (oldGrid WingedGrid) Kis(options ConwayOptions) (WingedGrid, error) {
+	faceVertices, err := oldGrid.conwayFaceVertices("kis")
+	if err != nil {
+		return WingedGrid{}, err
+	}
+	var coords [][3]float64 = oldGrid.vertexCoords()
+	var faces [][]int32
+	for _, vertices := range faceVertices {
+		var center int32 = int32(len(coords))
+		coords = append(coords, oldGrid.faceVertexCenter(vertices))
+		for k, vertexIndex := range vertices {
+			faces = append(faces, []int32{vertexIndex, vertices[(k+1)%len(vertices)], center})
+		}
+	}
+	return oldGrid.conwayResult(coords, faces, options)
+}
+
+// Conway's t: each vertex cut off. Vertices 2e and 2e+1 are on edge e, near
+// FirstVertexA and FirstVertexB. Faces are the old faces with twice the
+// corners, followed by a face for each old vertex.
+func (oldGrid WingedGrid) Truncate(options ConwayOptions) (WingedGrid, error) {
+	faceVertices, err := oldGrid.conwayFaceVertices("truncate")
+	if err != nil {
+		return WingedGrid{}, err
+	}
+	var coords [][3]float64 = make([][3]float64, 2*len(oldGrid.Edges))
+	for edgeIndex, edge := range oldGrid.Edges {
+		coords[2*edgeIndex] = oldGrid.edgePoint(edge, edge.FirstVertexA, 1.0/3)
+		coords[2*edgeIndex+1] = oldGrid.edgePoint(edge, edge.FirstVertexB, 1.0/3)
+	}
+	var faces [][]int32 = make([][]int32, 0, len(oldGrid.Faces)+len(oldGrid.Vertices))
+	for faceIndex, vertices := range faceVertices {
+		var face []int32
+		for k, edgeIndex := range oldGrid.Faces[faceIndex].Edges {
+			face = append(face,
+				oldGrid.nearEdgeVertex(0, edgeIndex, vertices[k]),
+				oldGrid.nearEdgeVertex(0, edgeIndex, vertices[(k+1)%len(vertices)]))
+		}
+		faces = append(faces, face)
+	}
+	for vertexIndex, vertex := range oldGrid.Vertices {
+		var face []int32
+		for _, edgeIndex := range vertex.Edges {
+			face = append(face, oldGrid.nearEdgeVertex(0, edgeIndex, int32(vertexIndex)))
+		}
+		faces = append(faces, face)
+	}
+	return oldGrid.conwayResult(coords, faces, options)
+}
+
+// Conway's c: each edge replaced by a hexagon. The old vertices keep their
+// index, followed by a copy of each face corner moved toward its center, face
+// by face. Faces are the shrunk old faces, then a hexagon for each edge.
+func (oldGrid WingedGrid) Chamfer(options ConwayOptions) (WingedGrid, error) {
+	faceVertices, err := oldGrid.conwayFaceVertices("chamfer")
+	if err != nil {
+		return WingedGrid{}, err
+	}
+	var coords [][3]float64 = oldGrid.vertexCoords()
+	corners, faces := oldGrid.insetFaces(faceVertices, &coords)
+	for edgeIndex, edge := range oldGrid.Edges {
+		var a, b int32 = edge.FirstVertexA, edge.FirstVertexB
+		insideA, insideB := oldGrid.insetEdge(corners, int32(edgeIndex))
+		faces = append(faces, []int32{a, insideB[0], insideB[1], b, insideA[1], insideA[0]})
+	}
+	return oldGrid.conwayResult(coords, faces, options)
+}
+
+// Conway's e: faces pulled apart, with a quad in each gap between two faces
+// and a face in each gap around a vertex. Vertices are copies of each face
+// corner moved toward its center, face by face. Faces are the shrunk old
+// faces, then a quad for each edge, then a face for each vertex.
+func (oldGrid WingedGrid) Expand(options ConwayOptions) (WingedGrid, error) {
+	faceVertices, err := oldGrid.conwayFaceVertices("expand")
+	if err != nil {
+		return WingedGrid{}, err
+	}
+	var coords [][3]float64
+	corners, faces := oldGrid.insetFaces(faceVertices, &coords)
+	for edgeIndex := range oldGrid.Edges {
+		insideA, insideB := oldGrid.insetEdge(corners, int32(edgeIndex))
+		faces = append(faces, []int32{insideB[0], insideB[1], insideA[1], insideA[0]})
+	}
+	for vertexIndex, vertex := range oldGrid.Vertices {
+		var face []int32
+		for _, edgeIndex := range vertex.Edges {
+			// the corner of the face where the edge starts here
+			var edge WingedEdge = oldGrid.Edges[edgeIndex]
+			var faceIndex int32 = edge.FaceB
+			if edge.FirstVertexA == int32(vertexIndex) {
+				faceIndex = edge.FaceA
+			}
+			face = append(face, corners[faceIndex]+int32(oldGrid.edgePosition(faceIndex, edgeIndex)))
+		}
+		faces = append(faces, face)
+	}
+	return oldGrid.conwayResult(coords, faces, options)
+}
+
+// Conway's g: each face split into pentagons around its center, turning the
+// same way. The old vertices keep their index, followed by vertices 2e and
+// 2e+1 on edge e as for Truncate, then the face centers. Old face f with n
+// corners becomes n pentagons, one per corner in order, starting at the sum
+// of the sizes of the faces before it.
+func (oldGrid WingedGrid) Gyro(options ConwayOptions) (WingedGrid, error) {
+	faceVertices, err := oldGrid.conwayFaceVertices("gyro")
+	if err != nil {
+		return WingedGrid{}, err
+	}
+	var coords [][3]float64 = oldGrid.vertexCoords()
+	var edgeOffset int32 = int32(len(coords))
+	for _, edge := range oldGrid.Edges {
+		coords = append(coords, oldGrid.edgePoint(edge, edge.FirstVertexA, 1.0/3), oldGrid.edgePoint(edge, edge.FirstVertexB, 1.0/3))
+	}
+	var faces [][]int32
+	for faceIndex, vertices := range faceVertices {
+		var center int32 = int32(len(coords))
+		coords = append(coords, oldGrid.faceVertexCenter(vertices))
+		var edges []int32 = oldGrid.Faces[faceIndex].Edges
+		for k, vertexIndex := range vertices {
+			var prevVertex int32 = vertices[(k+len(vertices)-1)%len(vertices)]
+			var prevEdge int32 = edges[(k+len(edges)-1)%len(edges)]
+			faces = append(faces, []int32{
+				center,
+				oldGrid.nearEdgeVertex(edgeOffset, prevEdge, prevVertex),
+				oldGrid.nearEdgeVertex(edgeOffset, prevEdge, vertexIndex),
+				vertexIndex,
+				oldGrid.nearEdgeVertex(edgeOffset, edges[k], vertexIndex),
+			})
+		}
+	}
+	return oldGrid.conwayResult(coords, faces, options)
+}
+
+// Conway's s, the dual of Gyro: each face surrounded by triangles, with a
+// vertex for each face of Gyro and a face for each of its vertices.
+func (oldGrid WingedGrid) Snub(options ConwayOptions) (WingedGrid, error) {
+	gyro, err := oldGrid.Gyro(ConwayOptions{})
+	if err != nil {
+		return WingedGrid{}, err
+	}
+	snub, err := gyro.CreateDual()
+	if err != nil {
+		return WingedGrid{}, err
+	}
+	if options.Spherical {
+		err = snub.projectToSphere(oldGrid.meanRadius())
+		if err != nil {
+			return WingedGrid{}, err
+		}
+	}
+	return snub, nil
+}
+
+// Conway's j, the dual of Ambo: a quad across each edge, joining its ends to
+// the centers of the faces on either side. The old vertices keep their index,
+// followed by the face centers, and face e is across edge e.
+func (oldGrid WingedGrid) Join(options ConwayOptions) (WingedGrid, error) {
+	faceVertices, err := oldGrid.conwayFaceVertices("join")
+	if err != nil {
+		return WingedGrid{}, err
+	}
+	var coords [][3]float64 = oldGrid.vertexCoords()
+	var faceOffset int32 = int32(len(coords))
+	for _, vertices := range faceVertices {
+		coords = append(coords, oldGrid.faceVertexCenter(vertices))
+	}
+	var faces [][]int32 = make([][]int32, len(oldGrid.Edges))
+	for edgeIndex, edge := range oldGrid.Edges {
+		faces[edgeIndex] = []int32{edge.FirstVertexA, faceOffset + edge.FaceB, edge.FirstVertexB, faceOffset + edge.FaceA}
+	}
+	return oldGrid.conwayResult(coords, faces, options)
+}
+
+/******************* Helpers ********************/
+
+// Returns the face vertex lists, or an error if the grid has a boundary
+func (oldGrid WingedGrid) conwayFaceVertices(operator string) ([][]int32, error) {
+	for edgeIndex, edge := range oldGrid.Edges {
+		if edge.IsBoundary() {
+			return nil, fmt.Errorf("%w: %s at boundary edge %d", ErrOpenSurface, operator, edgeIndex)
+		}
+	}
+	return oldGrid.faceVertexLists()
+}
+
+// Builds the new grid, moved to the sphere if asked
+func (oldGrid WingedGrid) conwayResult(coords [][3]float64, faces [][]int32, options ConwayOptions) (WingedGrid, error) {
+	newGrid, err := NewGridFromPolygons(coords, faces)
+	if err != nil {
+		return WingedGrid{}, err
+	}
+	if options.Spherical {
+		err = newGrid.projectToSphere(oldGrid.meanRadius())
+		if err != nil {
+			return WingedGrid{}, err
+		}
+	}
+	return newGrid, nil
+}
+
+// Moves every vertex to the sphere of the radius, returning an error if that
+// leaves the grid invalid, as a vertex at the origin or two neighbors on the
+// same line through it do
+func (theGrid WingedGrid) projectToSphere(radius float64) error {
+	theGrid.NormalizeVerticesToDistanceFromOrigin(radius)
+	return theGrid.Validate(ValidateOptions{MaxViolations: 10}).Err()
+}
+
+func (theGrid WingedGrid) vertexCoords() [][3]float64 {
+	var coords [][3]float64 = make([][3]float64, len(theGrid.Vertices))
+	for index, vertex := range theGrid.Vertices {
+		coords[index] = vertex.Coords
+	}
+	return coords
+}
+
+func (theGrid WingedGrid) faceVertexCenter(vertices []int32) [3]float64 {
+	var center [3]float64
+	for _, vertexIndex := range vertices {
+		center = vectorAdd(center, theGrid.Vertices[vertexIndex].Coords)
+	}
+	return vectorScale(center, 1/float64(len(vertices)))
+}
+
+// Returns the point the fraction of the way along the edge from one end
+func (theGrid WingedGrid) edgePoint(edge WingedEdge, from int32, fraction float64) [3]float64 {
+	var to int32 = edge.FirstVertexB
+	if from == edge.FirstVertexB {
+		to = edge.FirstVertexA
+	}
+	var start [3]float64 = theGrid.Vertices[from].Coords
+	return vectorAdd(start, vectorScale(vectorSub(theGrid.Vertices[to].Coords, start), fraction))
+}
+
+// Returns the index of the vertex on the edge nearer the given end, with two
+// vertices per edge starting at offset
+func (theGrid WingedGrid) nearEdgeVertex(offset, edgeIndex, vertexIndex int32) int32 {
+	if theGrid.Edges[edgeIndex].FirstVertexA == vertexIndex {
+		return offset + 2*edgeIndex
+	}
+	return offset + 2*edgeIndex + 1
+}
+
+// Returns where the edge is in the face's edge list, which is also the corner
+// the edge starts from
+func (theGrid WingedGrid) edgePosition(faceIndex, edgeIndex int32) int {
+	for k, otherIndex := range theGrid.Faces[faceIndex].Edges {
+		if otherIndex == edgeIndex {
+			return k
+		}
+	}
+	return -1
+}
+
+// Appends a copy of each face corner moved toward the face center, returning
+// the index of each face's first copy and the faces of the copies
+func (theGrid WingedGrid) insetFaces(faceVertices [][]int32, coords *[][3]float64) ([]int32, [][]int32) {
+	var corners []int32 = make([]int32, len(faceVertices))
+	var faces [][]int32 = make([][]int32, len(faceVertices))
+	for faceIndex, vertices := range faceVertices {
+		var center [3]float64 = theGrid.faceVertexCenter(vertices)
+		corners[faceIndex] = int32(len(*coords))
+		for k, vertexIndex := range vertices {
+			var corner [3]float64 = theGrid.Vertices[vertexIndex].Coords
+			*coords = append(*coords, vectorAdd(corner, vectorScale(vectorSub(center, corner), 1.0/3)))
+			faces[faceIndex] = append(faces[faceIndex], corners[faceIndex]+int32(k))
+		}
+	}
+	return corners, faces
+}
+
+// Returns the moved copies of the edge's ends inside FaceA and inside FaceB,
+// each as the copy of FirstVertexA then FirstVertexB
+func (theGrid WingedGrid) insetEdge(corners []int32, edgeIndex int32) ([2]int32, [2]int32) {
+	var edge WingedEdge = theGrid.Edges[edgeIndex]
+	var sizeA, sizeB int = len(theGrid.Faces[edge.FaceA].Edges), len(theGrid.Faces[edge.FaceB].Edges)
+	// the edge starts from FirstVertexA in FaceA, and FirstVertexB in FaceB
+	var positionA, positionB int = theGrid.edgePosition(edge.FaceA, edgeIndex), theGrid.edgePosition(edge.FaceB, edgeIndex)
+	var insideA [2]int32 = [2]int32{corners[edge.FaceA] + int32(positionA), corners[edge.FaceA] + int32((positionA+1)%sizeA)}
+	var insideB [2]int32 = [2]int32{corners[edge.FaceB] + int32((positionB+1)%sizeB), corners[edge.FaceB] + int32(positionB)}
+	return insideA, insideB
+}
+
+func (theGrid WingedGrid) meanRadius() float64 {
+	var radius float64
+	for _, vertex := range theGrid.Vertices {
+		radius += vectorLength(vertex.Coords)
+	}
+	return radius / float64(len(theGrid.Vertices))
+}
@@ -0,0 +1,148 @@
+package wingedGrid
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+type conwayOperator struct {
+	name  string
+	apply func(WingedGrid, ConwayOptions) (WingedGrid, error)
+	// counts from the vertices, edges and faces of the seed
+	counts func(v, e, f int) (int, int, int)
+}
+
+var conwayOperators []conwayOperator = []conwayOperator{
+	{"ambo", WingedGrid.Ambo, func(v, e, f int) (int, int, int) { return e, 2 * e, f + v }},
+	{"kis", WingedGrid.Kis, func(v, e, f int) (int, int, int) { return v + f, 3 * e, 2 * e }},
+	{"truncate", WingedGrid.Truncate, func(v, e, f int) (int, int, int) { return 2 * e, 3 * e, f + v }},
+	{"chamfer", WingedGrid.Chamfer, func(v, e, f int) (int, int, int) { return v + 2*e, 4 * e, f + e }},
+	{"expand", WingedGrid.Expand, func(v, e, f int) (int, int, int) { return 2 * e, 4 * e, v + e + f }},
+	{"gyro", WingedGrid.Gyro, func(v, e, f int) (int, int, int) { return v + 2*e + f, 5 * e, 2 * e }},
+	{"snub", WingedGrid.Snub, func(v, e, f int) (int, int, int) { return 2 * e, 5 * e, v + 2*e + f }},
+	{"join", WingedGrid.Join, func(v, e, f int) (int, int, int) { return v + f, 2 * e, e }},
+}
+
+func TestConwayOperators(t *testing.T) {
+	cube, _ := BaseCube()
+	icosahedron, _ := BaseIcosahedron()
+	dodecahedron, _ := icosahedron.CreateDual()
+	for _, seed := range []WingedGrid{cube, icosahedron, dodecahedron} {
+		var v, e, f int = len(seed.Vertices), len(seed.Edges), len(seed.Faces)
+		for _, operator := range conwayOperators {
+			for _, spherical := range []bool{false, true} {
+				grid, err := operator.apply(seed, ConwayOptions{Spherical: spherical})
+				if err != nil {
+					t.Fatalf("Failed to apply %s to %d faces: %s", operator.name, f, err)
+				}
+				if report := grid.Validate(ValidateOptions{Spherical: true}); !report.Valid() {
+					t.Fatalf("%s of %d faces invalid: %s", operator.name, f, report.Err())
+				}
+				vertices, edges, faces := operator.counts(v, e, f)
+				if len(grid.Vertices) != vertices || len(grid.Edges) != edges || len(grid.Faces) != faces {
+					t.Errorf("%s of %d faces has counts %d %d %d, expected %d %d %d", operator.name, f,
+						len(grid.Vertices), len(grid.Edges), len(grid.Faces), vertices, edges, faces)
+				}
+				if !spherical {
+					continue
+				}
+				var radius float64 = vectorLength(seed.Vertices[0].Coords)
+				for index, vertex := range grid.Vertices {
+					if math.Abs(vectorLength(vertex.Coords)-radius) > 1e-12 {
+						t.Errorf("%s vertex %d is off the sphere", operator.name, index)
+						break
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestConwayCube(t *testing.T) {
+	cube, _ := BaseCube()
+	var faceSizes = func(grid WingedGrid) map[int]int {
+		var sizes map[int]int = make(map[int]int)
+		for _, face := range grid.Faces {
+			sizes[len(face.Edges)]++
+		}
+		return sizes
+	}
+	var expected map[string]map[int]int = map[string]map[int]int{
+		"ambo":     {3: 8, 4: 6},
+		"kis":      {3: 24},
+		"truncate": {3: 8, 8: 6},
+		"chamfer":  {4: 6, 6: 12},
+		"expand":   {3: 8, 4: 18},
+		"gyro":     {5: 24},
+		"snub":     {3: 32, 4: 6},
+		"join":     {4: 12},
+	}
+	for _, operator := range conwayOperators {
+		grid, err := operator.apply(cube, ConwayOptions{})
+		if err != nil {
+			t.Fatalf("Failed to apply %s: %s", operator.name, err)
+		}
+		var sizes map[int]int = faceSizes(grid)
+		if len(sizes) != len(expected[operator.name]) {
+			t.Errorf("%s of the cube has faces %v, expected %v", operator.name, sizes, expected[operator.name])
+			continue
+		}
+		for size, count := range expected[operator.name] {
+			if sizes[size] != count {
+				t.Errorf("%s of the cube has faces %v, expected %v", operator.name, sizes, expected[operator.name])
+				break
+			}
+		}
+	}
+
+	// ambo vertices are the edge midpoints
+	ambo, _ := cube.Ambo(ConwayOptions{})
+	for edgeIndex, edge := range cube.Edges {
+		var midpoint [3]float64 = vectorScale(vectorAdd(cube.Vertices[edge.FirstVertexA].Coords, cube.Vertices[edge.FirstVertexB].Coords), 0.5)
+		if distanceBetween3Points(midpoint, ambo.Vertices[edgeIndex].Coords) > 1e-12 {
+			t.Errorf("Ambo vertex %d isn't the middle of edge %d", edgeIndex, edgeIndex)
+		}
+	}
+	// kis and join keep the old vertices
+	for _, operator := range []func(WingedGrid, ConwayOptions) (WingedGrid, error){WingedGrid.Kis, WingedGrid.Join} {
+		grid, _ := operator(cube, ConwayOptions{})
+		for index, vertex := range cube.Vertices {
+			if grid.Vertices[index].Coords != vertex.Coords {
+				t.Errorf("Vertex %d moved", index)
+			}
+		}
+	}
+}
+
+func TestConwayOpenGrid(t *testing.T) {
+	patch, err := PlanarHexHexagon(2)
+	if err != nil {
+		t.Fatalf("Failed to create patch: %s", err)
+	}
+	for _, operator := range conwayOperators {
+		if _, err := operator.apply(patch, ConwayOptions{}); !errors.Is(err, ErrOpenSurface) {
+			t.Errorf("Expected %s to fail with ErrOpenSurface, got %v", operator.name, err)
+		}
+	}
+}
+
+func TestConwaySphericalDegenerate(t *testing.T) {
+	// a flat map with a vertex at the origin, which can't be moved to a
+	// sphere, and neighbors on the same line through it, which merge there
+	torus, err := PlanarTriangleMap(6, 4, WrapXY)
+	if err != nil {
+		t.Fatalf("Failed to create map: %s", err)
+	}
+	for _, operator := range conwayOperators {
+		if _, err := operator.apply(torus, ConwayOptions{}); err != nil {
+			t.Errorf("%s failed without moving to the sphere: %s", operator.name, err)
+		}
+		switch operator.name {
+		case "kis", "truncate", "chamfer", "gyro", "join":
+			if _, err := operator.apply(torus, ConwayOptions{Spherical: true}); err == nil {
+				t.Errorf("Expected %s to fail moving to the sphere", operator.name)
+			}
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package wingedGrid
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Triangulates points on a sphere centered on the origin, so that no point is
+// inside the circumcircle of a triangle. Only the direction of each point from
+// the origin is used, and vertex i of the grid is at points[i].
+//
+// The triangles are the faces of the convex hull of the points. When the
+// points all lie within one hemisphere the hull faces seen from the origin
+// aren't Delaunay triangles, and are left out, giving a grid with a boundary
+// around the points.
+//
+// CreateDual of the result has the topology of the spherical Voronoi diagram,
+// with dual vertices at triangle centroids rather than circumcenters.
+func SphericalDelaunay(points [][3]float64) (WingedGrid, error) {
+	if len(points) < 4 {
+		return WingedGrid{}, errors.New("Spherical Delaunay needs at least four points")
+	}
+	var directions [][3]float64 = make([][3]float64, len(points))
+	for index, point := range points {
+		var length float64 = vectorLength(point)
+		if length == 0 {
+			return WingedGrid{}, fmt.Errorf("Point %d is at the origin", index)
+		}
+		directions[index] = [3]float64{point[0] / length, point[1] / length, point[2] / length}
+	}
+	hull, err := newQuickhull(directions)
+	if err != nil {
+		return WingedGrid{}, err
+	}
+
+	var faces [][]int32
+	var used []bool = make([]bool, len(points))
+	for _, triangle := range hull.triangles() {
+		// the plane must pass the origin on its inner side
+		if triangle.offset <= hull.epsilon {
+			continue
+		}
+		faces = append(faces, []int32{triangle.vertices[0], triangle.vertices[1], triangle.vertices[2]})
+		for _, vertexIndex := range triangle.vertices {
+			used[vertexIndex] = true
+		}
+	}
+	for index := range used {
+		if !used[index] {
+			return WingedGrid{}, fmt.Errorf("Point %d is in the same direction as another point, or on a great circle bounding the rest", index)
+		}
+	}
+	return NewGridFromPolygons(points, faces)
+}
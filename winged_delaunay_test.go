@@ -0,0 +1,158 @@
+package wingedGrid
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func randomSpherePoints(count int, seed int64) [][3]float64 {
+	var random *rand.Rand = rand.New(rand.NewSource(seed))
+	var points [][3]float64 = make([][3]float64, count)
+	for i := range points {
+		var point [3]float64 = [3]float64{random.NormFloat64(), random.NormFloat64(), random.NormFloat64()}
+		var length float64 = vectorLength(point)
+		points[i] = [3]float64{point[0] / length, point[1] / length, point[2] / length}
+	}
+	return points
+}
+
+// checks no point is inside the circumcircle of a face of a closed
+// triangulation of points on the unit sphere
+func checkEmptyCircumcircles(t *testing.T, grid WingedGrid) {
+	faceVertices := mustFaceVertexLists(t, grid)
+	for faceIndex, vertices := range faceVertices {
+		var a, b, c [3]float64 = grid.Vertices[vertices[0]].Coords, grid.Vertices[vertices[1]].Coords, grid.Vertices[vertices[2]].Coords
+		var normal [3]float64 = vectorCross(vectorSub(b, a), vectorSub(c, a))
+		var length float64 = vectorLength(normal)
+		normal = [3]float64{normal[0] / length, normal[1] / length, normal[2] / length}
+		var offset float64 = vectorDot(normal, a)
+		for index, vertex := range grid.Vertices {
+			if vectorDot(normal, vertex.Coords)-offset > 1e-12 {
+				t.Fatalf("Vertex %d is inside the circumcircle of face %d", index, faceIndex)
+			}
+		}
+	}
+}
+
+func TestSphericalDelaunayIcosahedron(t *testing.T) {
+	base, _ := BaseIcosahedron()
+	var points [][3]float64
+	for _, vertex := range base.Vertices {
+		points = append(points, vertex.Coords)
+	}
+	grid, err := SphericalDelaunay(points)
+	if err != nil {
+		t.Fatalf("Failed to triangulate: %s", err)
+	}
+	if len(grid.Faces) != 20 || len(grid.Edges) != 30 {
+		t.Errorf("Expected 20 faces and 30 edges, got %d and %d", len(grid.Faces), len(grid.Edges))
+	}
+	for index, vertex := range grid.Vertices {
+		if vertex.Coords != points[index] {
+			t.Errorf("Vertex %d moved from %v to %v", index, points[index], vertex.Coords)
+		}
+	}
+	if report := grid.Validate(ValidateOptions{Spherical: true}); !report.Valid() {
+		t.Errorf("Triangulation invalid: %s", report.Err())
+	}
+}
+
+func TestSphericalDelaunayRandom(t *testing.T) {
+	var points [][3]float64 = randomSpherePoints(2000, 1)
+	grid, err := SphericalDelaunay(points)
+	if err != nil {
+		t.Fatalf("Failed to triangulate: %s", err)
+	}
+	if len(grid.Faces) != 2*len(points)-4 {
+		t.Errorf("Expected %d faces, got %d", 2*len(points)-4, len(grid.Faces))
+	}
+	if report := grid.Validate(ValidateOptions{Spherical: true}); !report.Valid() {
+		t.Fatalf("Triangulation invalid: %s", report.Err())
+	}
+	checkEmptyCircumcircles(t, grid)
+
+	voronoi, err := grid.CreateDual()
+	if err != nil {
+		t.Fatalf("Failed to create dual: %s", err)
+	}
+	if report := voronoi.Validate(ValidateOptions{Spherical: true}); !report.Valid() {
+		t.Errorf("Voronoi diagram invalid: %s", report.Err())
+	}
+}
+
+func TestSphericalDelaunayCospherical(t *testing.T) {
+	// the corners of a cube, four at a time on a circle, and scaled
+	var points [][3]float64
+	for _, z := range []float64{-2, 2} {
+		for _, y := range []float64{-2, 2} {
+			for _, x := range []float64{-2, 2} {
+				points = append(points, [3]float64{x, y, z})
+			}
+		}
+	}
+	grid, err := SphericalDelaunay(points)
+	if err != nil {
+		t.Fatalf("Failed to triangulate: %s", err)
+	}
+	if len(grid.Faces) != 12 {
+		t.Errorf("Expected 12 faces, got %d", len(grid.Faces))
+	}
+	if report := grid.Validate(ValidateOptions{Spherical: true}); !report.Valid() {
+		t.Errorf("Triangulation invalid: %s", report.Err())
+	}
+}
+
+func TestSphericalDelaunayHemisphere(t *testing.T) {
+	var points [][3]float64
+	for _, point := range randomSpherePoints(500, 2) {
+		if point[2] > 0.1 {
+			points = append(points, point)
+		}
+	}
+	grid, err := SphericalDelaunay(points)
+	if err != nil {
+		t.Fatalf("Failed to triangulate: %s", err)
+	}
+	if report := grid.Validate(ValidateOptions{Spherical: true}); !report.Valid() {
+		t.Fatalf("Triangulation invalid: %s", report.Err())
+	}
+	if euler := len(grid.Vertices) - len(grid.Edges) + len(grid.Faces); euler != 1 {
+		t.Errorf("Expected Euler characteristic 1, got %d", euler)
+	}
+	// the boundary is the spherical convex hull, with every face on the inside
+	for index, edge := range grid.Edges {
+		if !edge.IsBoundary() {
+			continue
+		}
+		var a, b [3]float64 = grid.Vertices[edge.FirstVertexA].Coords, grid.Vertices[edge.FirstVertexB].Coords
+		var normal [3]float64 = vectorCross(a, b)
+		for vertexIndex, vertex := range grid.Vertices {
+			if vectorDot(normal, vertex.Coords) < -1e-12 {
+				t.Fatalf("Vertex %d is outside boundary edge %d", vertexIndex, index)
+			}
+		}
+	}
+}
+
+func TestSphericalDelaunayInvalid(t *testing.T) {
+	var points [][3]float64 = randomSpherePoints(10, 3)
+	if _, err := SphericalDelaunay(points[:3]); err == nil {
+		t.Error("Expected an error for three points")
+	}
+	if _, err := SphericalDelaunay(append(points, [3]float64{})); err == nil {
+		t.Error("Expected an error for a point at the origin")
+	}
+	var doubled [3]float64 = [3]float64{2 * points[4][0], 2 * points[4][1], 2 * points[4][2]}
+	if _, err := SphericalDelaunay(append(points, doubled)); err == nil {
+		t.Error("Expected an error for a repeated direction")
+	}
+	var circle [][3]float64
+	for i := 0; i < 8; i++ {
+		var angle float64 = float64(i) * math.Pi / 4
+		circle = append(circle, [3]float64{math.Cos(angle), math.Sin(angle), 0})
+	}
+	if _, err := SphericalDelaunay(circle); err == nil {
+		t.Error("Expected an error for points on a great circle")
+	}
+}
@@ -0,0 +1,221 @@
+package wingedGrid
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"math"
+)
+
+// Coordinate deltas between grids sharing the same topology, for sending
+// vertex movement without resending the whole grid.
+//
+// Changes are quantized to a multiple of twice the error bound, so each
+// applied coordinate is within the bound of the target. Apply deltas to a
+// copy kept by the sender and diff against that copy, otherwise the rounding
+// of successive deltas accumulates.
+
+// the vertex coordinate changes between two grids with the same topology
+type CoordDelta struct {
+	// TopologyChecksum of the grids the delta is between
+	Checksum uint64
+	// size of one quantization step
+	Step float64
+	// changed vertices in increasing order
+	Vertices []int32
+	// change of each vertex in Vertices, in steps
+	Changes [][3]int64
+}
+
+// Returns a checksum of the element counts and the vertices, faces and next
+// edges of each edge, which fix the rest of the topology. Grids with different
+// topology get different checksums with high probability. Coordinates are not
+// included, nor the derived prev edges and face and vertex edge lists, whose
+// start a decoder may choose differently, so a grid sent with Minimal keeps
+// its checksum.
+func (theGrid WingedGrid) TopologyChecksum() uint64 {
+	var hash = fnv.New64a()
+	var scratch [4]byte
+	var write = func(value int32) {
+		binary.LittleEndian.PutUint32(scratch[:], uint32(value))
+		hash.Write(scratch[:])
+	}
+	write(int32(len(theGrid.Faces)))
+	write(int32(len(theGrid.Edges)))
+	write(int32(len(theGrid.Vertices)))
+	for _, edge := range theGrid.Edges {
+		write(edge.FirstVertexA)
+		write(edge.FirstVertexB)
+		write(edge.FaceA)
+		write(edge.FaceB)
+		write(edge.NextA)
+		write(edge.NextB)
+	}
+	return hash.Sum64()
+}
+
+// Returns the delta that moves the vertices of oldGrid to within maxError of
+// the vertices of this grid in each coordinate. Vertices that moved less than
+// maxError are left out. Returns an error if the topology of the grids differs.
+func (newGrid WingedGrid) CoordDeltaFrom(oldGrid WingedGrid, maxError float64) (CoordDelta, error) {
+	var delta CoordDelta
+	if !(maxError > 0) || math.IsInf(maxError, 0) {
+		return delta, errors.New("Error bound must be positive")
+	}
+	delta.Checksum = newGrid.TopologyChecksum()
+	if delta.Checksum != oldGrid.TopologyChecksum() {
+		return delta, errors.New("Grids have different topology")
+	}
+	delta.Step = 2 * maxError
+
+	for index, vertex := range newGrid.Vertices {
+		var change [3]int64
+		var changed bool
+		for i := 0; i < 3; i++ {
+			var steps float64 = math.Round((vertex.Coords[i] - oldGrid.Vertices[index].Coords[i]) / delta.Step)
+			if math.IsNaN(steps) || math.Abs(steps) > math.MaxInt64/2 {
+				return delta, fmt.Errorf("Change of vertex %d can't be quantized", index)
+			}
+			change[i] = int64(steps)
+			changed = changed || change[i] != 0
+		}
+		if changed {
+			delta.Vertices = append(delta.Vertices, int32(index))
+			delta.Changes = append(delta.Changes, change)
+		}
+	}
+	return delta, nil
+}
+
+// Moves the vertices of this grid by the delta, returning an error without
+// changing the grid if the delta was made for a different topology.
+func (theGrid WingedGrid) ApplyCoordDelta(delta CoordDelta) error {
+	if delta.Checksum != theGrid.TopologyChecksum() {
+		return errors.New("Delta is for a different topology")
+	}
+	if len(delta.Vertices) != len(delta.Changes) {
+		return errors.New("Delta has mismatched vertices and changes")
+	}
+	if !validDeltaStep(delta.Step) {
+		return fmt.Errorf("Delta step %v must be finite and positive", delta.Step)
+	}
+	for _, vertexIndex := range delta.Vertices {
+		if !indexInRange(vertexIndex, len(theGrid.Vertices)) {
+			return fmt.Errorf("Delta vertex %d out of range", vertexIndex)
+		}
+	}
+	for i, vertexIndex := range delta.Vertices {
+		var coords *[3]float64 = &theGrid.Vertices[vertexIndex].Coords
+		coords[0] += float64(delta.Changes[i][0]) * delta.Step
+		coords[1] += float64(delta.Changes[i][1]) * delta.Step
+		coords[2] += float64(delta.Changes[i][2]) * delta.Step
+	}
+	return nil
+}
+
+func validDeltaStep(step float64) bool {
+	return step > 0 && !math.IsInf(step, 0)
+}
+
+/******************* Delta Wire Format ********************/
+
+// All values little-endian, header is
+//   magic    [4]byte "WGDL"
+//   version  uint8
+//   checksum uint64
+//   step     float64
+//   count    uvarint
+// followed by each changed vertex as the uvarint gap from the previous
+// vertex index and the three changes as varints.
+
+const (
+	deltaMagic   = "WGDL"
+	deltaVersion = 1
+)
+
+// Encodes the delta, implementing encoding.BinaryMarshaler
+func (delta CoordDelta) MarshalBinary() ([]byte, error) {
+	if len(delta.Vertices) != len(delta.Changes) {
+		return nil, errors.New("Delta has mismatched vertices and changes")
+	}
+	var buffer bytes.Buffer
+	var scratch [binary.MaxVarintLen64]byte
+	buffer.WriteString(deltaMagic)
+	buffer.WriteByte(deltaVersion)
+	binary.LittleEndian.PutUint64(scratch[:], delta.Checksum)
+	buffer.Write(scratch[:8])
+	binary.LittleEndian.PutUint64(scratch[:], math.Float64bits(delta.Step))
+	buffer.Write(scratch[:8])
+	buffer.Write(scratch[:binary.PutUvarint(scratch[:], uint64(len(delta.Vertices)))])
+
+	var previous int32 = -1
+	for i, vertexIndex := range delta.Vertices {
+		if vertexIndex <= previous {
+			return nil, errors.New("Delta vertices must be increasing")
+		}
+		buffer.Write(scratch[:binary.PutUvarint(scratch[:], uint64(vertexIndex-previous))])
+		previous = vertexIndex
+		for _, change := range delta.Changes[i] {
+			buffer.Write(scratch[:binary.PutVarint(scratch[:], change)])
+		}
+	}
+	return buffer.Bytes(), nil
+}
+
+// Decodes a delta, implementing encoding.BinaryUnmarshaler
+func (delta *CoordDelta) UnmarshalBinary(data []byte) error {
+	var reader *bytes.Reader = bytes.NewReader(data)
+	var header [4 + 1 + 8 + 8]byte
+	if _, err := io.ReadFull(reader, header[:]); err != nil {
+		return io.ErrUnexpectedEOF
+	}
+	if string(header[:4]) != deltaMagic {
+		return errors.New("Not a WingedGrid coordinate delta")
+	}
+	if header[4] != deltaVersion {
+		return fmt.Errorf("Unsupported coordinate delta version %d", header[4])
+	}
+	var decoded CoordDelta
+	decoded.Checksum = binary.LittleEndian.Uint64(header[5:])
+	decoded.Step = math.Float64frombits(binary.LittleEndian.Uint64(header[13:]))
+	if !validDeltaStep(decoded.Step) {
+		return fmt.Errorf("Delta step %v must be finite and positive", decoded.Step)
+	}
+
+	count, err := binary.ReadUvarint(reader)
+	if err != nil {
+		return io.ErrUnexpectedEOF
+	}
+	// every vertex takes at least four bytes
+	if count > uint64(reader.Len()/4) {
+		return errors.New("Delta vertex count exceeds data")
+	}
+	decoded.Vertices = make([]int32, count)
+	decoded.Changes = make([][3]int64, count)
+	var previous int64 = -1
+	for i := range decoded.Vertices {
+		gap, err := binary.ReadUvarint(reader)
+		if err != nil {
+			return io.ErrUnexpectedEOF
+		}
+		if gap == 0 || gap > math.MaxInt32 || previous+int64(gap) > math.MaxInt32 {
+			return errors.New("Delta vertex index out of range")
+		}
+		previous = previous + int64(gap)
+		decoded.Vertices[i] = int32(previous)
+		for j := range decoded.Changes[i] {
+			decoded.Changes[i][j], err = binary.ReadVarint(reader)
+			if err != nil {
+				return io.ErrUnexpectedEOF
+			}
+		}
+	}
+	if reader.Len() != 0 {
+		return fmt.Errorf("%d trailing bytes after delta", reader.Len())
+	}
+	*delta = decoded
+	return nil
+}
@@ -0,0 +1,151 @@
+package wingedGrid
+
+import (
+	"bytes"
+	"math"
+	"testing"
+)
+
+// returns a deep copy of the grid
+func copyGrid(theGrid WingedGrid) WingedGrid {
+	data, _ := theGrid.MarshalBinary()
+	var copied WingedGrid
+	copied.UnmarshalBinary(data)
+	return copied
+}
+
+func TestCoordDeltaRoundTrip(t *testing.T) {
+	base, _ := BaseIcosahedron()
+	oldGrid, _ := base.SubdivideTriangles(4)
+	newGrid := copyGrid(oldGrid)
+	newGrid.UniformVertsOnUnitSphere(5)
+	// the client only has the old positions, on a sphere of the old radius
+	client := copyGrid(oldGrid)
+
+	const maxError = 1e-5
+	delta, err := newGrid.CoordDeltaFrom(client, maxError)
+	if err != nil {
+		t.Fatalf("Failed to create delta: %s", err)
+	}
+	if len(delta.Vertices) != len(newGrid.Vertices) {
+		t.Errorf("Expected all %d vertices to change, got %d", len(newGrid.Vertices), len(delta.Vertices))
+	}
+
+	data, err := delta.MarshalBinary()
+	if err != nil {
+		t.Fatalf("Failed to marshal delta: %s", err)
+	}
+	full, _ := newGrid.MarshalBinary()
+	if len(data)*3 > len(full) {
+		t.Errorf("Delta is %d bytes, full grid is %d", len(data), len(full))
+	}
+	var received CoordDelta
+	err = received.UnmarshalBinary(data)
+	if err != nil {
+		t.Fatalf("Failed to unmarshal delta: %s", err)
+	}
+
+	err = client.ApplyCoordDelta(received)
+	if err != nil {
+		t.Fatalf("Failed to apply delta: %s", err)
+	}
+	for index, vertex := range newGrid.Vertices {
+		for i := 0; i < 3; i++ {
+			if math.Abs(vertex.Coords[i]-client.Vertices[index].Coords[i]) > maxError {
+				t.Fatalf("Vertex %d out of bound, expected %v got %v", index, vertex.Coords, client.Vertices[index].Coords)
+			}
+		}
+	}
+}
+
+func TestCoordDeltaSkipsUnchanged(t *testing.T) {
+	oldGrid, _ := BaseIcosahedron()
+	newGrid := copyGrid(oldGrid)
+	newGrid.Vertices[4].Coords[2] += 0.5
+	newGrid.Vertices[9].Coords[0] += 1e-9
+
+	delta, err := newGrid.CoordDeltaFrom(oldGrid, 1e-6)
+	if err != nil {
+		t.Fatalf("Failed to create delta: %s", err)
+	}
+	if len(delta.Vertices) != 1 || delta.Vertices[0] != 4 {
+		t.Errorf("Expected only vertex 4 to change, got %v", delta.Vertices)
+	}
+}
+
+func TestCoordDeltaRejectsOtherTopology(t *testing.T) {
+	base, _ := BaseIcosahedron()
+	dual, _ := base.CreateDual()
+	if _, err := dual.CoordDeltaFrom(base, 1e-3); err == nil {
+		t.Error("Expected an error diffing different topologies")
+	}
+
+	moved := copyGrid(base)
+	moved.Vertices[0].Coords[0] += 1
+	delta, _ := moved.CoordDeltaFrom(base, 1e-3)
+	if err := dual.ApplyCoordDelta(delta); err == nil {
+		t.Error("Expected an error applying a delta to a different topology")
+	}
+	// a single changed link changes the checksum
+	relinked := copyGrid(base)
+	relinked.Edges[3].NextA, relinked.Edges[3].PrevA = relinked.Edges[3].PrevA, relinked.Edges[3].NextA
+	if err := relinked.ApplyCoordDelta(delta); err == nil {
+		t.Error("Expected an error applying a delta to relinked edges")
+	}
+
+	data, _ := delta.MarshalBinary()
+	var received CoordDelta
+	if err := received.UnmarshalBinary(data[:len(data)-1]); err == nil {
+		t.Error("Expected an error for a truncated delta")
+	}
+}
+
+func TestCoordDeltaRejectsBadStep(t *testing.T) {
+	base, _ := BaseIcosahedron()
+	moved := copyGrid(base)
+	moved.Vertices[0].Coords[0] += 1
+	delta, _ := moved.CoordDeltaFrom(base, 1e-3)
+	for _, step := range []float64{0, -1e-3, math.NaN(), math.Inf(1)} {
+		var bad CoordDelta = delta
+		bad.Step = step
+		if err := copyGrid(base).ApplyCoordDelta(bad); err == nil {
+			t.Errorf("Expected an error applying step %v", step)
+		}
+		data, _ := bad.MarshalBinary()
+		var received CoordDelta
+		if err := received.UnmarshalBinary(data); err == nil {
+			t.Errorf("Expected an error decoding step %v", step)
+		}
+	}
+}
+
+func TestCoordDeltaAfterMinimalEncoding(t *testing.T) {
+	base, _ := BaseIcosahedron()
+	subdivided, _ := base.SubdivideTriangles(3)
+	dual, _ := base.CreateDual()
+	for _, grid := range []WingedGrid{base, subdivided, dual} {
+		var buffer bytes.Buffer
+		if err := grid.EncodeWithOptions(&buffer, WireOptions{Minimal: true}); err != nil {
+			t.Fatalf("Failed to encode: %s", err)
+		}
+		var client WingedGrid
+		if err := client.Decode(&buffer); err != nil {
+			t.Fatalf("Failed to decode: %s", err)
+		}
+		if client.TopologyChecksum() != grid.TopologyChecksum() {
+			t.Errorf("Checksum changed by minimal encoding")
+		}
+		moved := copyGrid(grid)
+		moved.Vertices[0].Coords[0] += 0.25
+		delta, err := moved.CoordDeltaFrom(grid, 1e-6)
+		if err != nil {
+			t.Fatalf("Failed to create delta: %s", err)
+		}
+		if err := client.ApplyCoordDelta(delta); err != nil {
+			t.Fatalf("Failed to apply delta to a minimal copy: %s", err)
+		}
+		if math.Abs(client.Vertices[0].Coords[0]-moved.Vertices[0].Coords[0]) > 1e-6 {
+			t.Errorf("Vertex 0 not moved, got %v", client.Vertices[0].Coords)
+		}
+	}
+}
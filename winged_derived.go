@@ -0,0 +1,216 @@
+package wingedGrid
+
+import (
+	"fmt"
+)
+
+// Regeneration of the information in a WingedGrid that is duplicated from
+// the edges for faster traversal.
+
+// Regenerates the edge lists of every face and vertex, in clockwise order,
+// from the edge table alone, and clears the vertex neighbor cache.
+// The number of faces and vertices is kept, and vertex coords are untouched.
+// Returns an error if the edges reference faces or vertices that don't exist,
+// or if following the edges around a face or vertex doesn't form a single loop,
+// or for a vertex on the boundary a single open fan.
+func (theGrid *WingedGrid) RebuildDerived() error {
+	err := theGrid.checkEdgeReferences()
+	if err != nil {
+		return err
+	}
+	faceEdges, err := theGrid.faceEdgeLoops()
+	if err != nil {
+		return err
+	}
+	vertexEdges, err := theGrid.vertexEdgeLoops()
+	if err != nil {
+		return err
+	}
+
+	// only modify the grid once everything has been found
+	for index := range theGrid.Faces {
+		theGrid.Faces[index].Edges = faceEdges[index]
+	}
+	theGrid.setVertexEdges(vertexEdges)
+	return nil
+}
+
+func (theGrid WingedGrid) setVertexEdges(vertexEdges [][]int32) {
+	for index := range theGrid.Vertices {
+		theGrid.Vertices[index].Edges = vertexEdges[index]
+		theGrid.Vertices[index].vertexNeighbors = nil
+	}
+}
+
+// Returns an error if any edge has an index out of range, other than the
+// missing face and edges on the outside of a boundary edge
+func (theGrid WingedGrid) checkEdgeReferences() error {
+	for index, edge := range theGrid.Edges {
+		if !indexInRange(edge.FaceA, len(theGrid.Faces)) {
+			return fmt.Errorf("Edge %d references face %d out of range", index, edge.FaceA)
+		}
+		var edgeIndices []int32 = []int32{edge.PrevA, edge.NextA}
+		if edge.FaceB != NoFace {
+			if !indexInRange(edge.FaceB, len(theGrid.Faces)) {
+				return fmt.Errorf("Edge %d references face %d out of range", index, edge.FaceB)
+			}
+			edgeIndices = append(edgeIndices, edge.PrevB, edge.NextB)
+		}
+		for _, vertexIndex := range [2]int32{edge.FirstVertexA, edge.FirstVertexB} {
+			if !indexInRange(vertexIndex, len(theGrid.Vertices)) {
+				return fmt.Errorf("Edge %d references vertex %d out of range", index, vertexIndex)
+			}
+		}
+		for _, edgeIndex := range edgeIndices {
+			if !indexInRange(edgeIndex, len(theGrid.Edges)) {
+				return fmt.Errorf("Edge %d references edge %d out of range", index, edgeIndex)
+			}
+		}
+	}
+	return nil
+}
+
+// Returns the clockwise edges of each face found by following the edges,
+// which must all be in range
+func (theGrid WingedGrid) faceEdgeLoops() ([][]int32, error) {
+	return edgeLoopsFor("Face", len(theGrid.Faces), theGrid.Edges, func(edge WingedEdge) [2]int32 {
+		return [2]int32{edge.FaceA, edge.FaceB}
+	}, func(edgeIndex, faceIndex int32) (int32, error) {
+		return theGrid.Edges[edgeIndex].NextEdgeForFace(faceIndex)
+	}, nil)
+}
+
+// Returns the clockwise edges around each vertex found by following the
+// edges, which must all be in range. Vertices on the boundary get an open fan.
+func (theGrid WingedGrid) vertexEdgeLoops() ([][]int32, error) {
+	return edgeLoopsFor("Vertex", len(theGrid.Vertices), theGrid.Edges, func(edge WingedEdge) [2]int32 {
+		return [2]int32{edge.FirstVertexA, edge.FirstVertexB}
+	}, func(edgeIndex, vertexIndex int32) (int32, error) {
+		return theGrid.Edges[edgeIndex].NextEdgeForVertex(vertexIndex)
+	}, func(edgeIndex, vertexIndex int32) (int32, error) {
+		return theGrid.Edges[edgeIndex].PrevEdgeForVertex(vertexIndex)
+	})
+}
+
+// Finds the loop of edges for each of count elements, starting from the first
+// edge referencing the element and expecting to visit every edge that does.
+// Missing faces are skipped. With prev given, a loop may also be an open fan
+// between edges where prev and next give NoEdge.
+func edgeLoopsFor(name string, count int, edges []WingedEdge, elementsOf func(WingedEdge) [2]int32, next, prev func(int32, int32) (int32, error)) ([][]int32, error) {
+	var start []int32 = make([]int32, count)
+	var sides []int = make([]int, count)
+	for i := range start {
+		start[i] = -1
+	}
+	for index, edge := range edges {
+		for _, element := range elementsOf(edge) {
+			if element == NoFace {
+				continue
+			}
+			if start[element] == -1 {
+				start[element] = int32(index)
+			}
+			sides[element]++
+		}
+	}
+
+	var loops [][]int32 = make([][]int32, count)
+	for index := range loops {
+		if start[index] == -1 {
+			return nil, fmt.Errorf("%s %d has no edges", name, index)
+		}
+		var element int32 = int32(index)
+		var elementPrev func(int32) (int32, error)
+		if prev != nil {
+			elementPrev = func(edgeIndex int32) (int32, error) {
+				return prev(edgeIndex, element)
+			}
+		}
+		loop, err := edgeLoop(start[index], sides[index], func(edgeIndex int32) (int32, error) {
+			return next(edgeIndex, element)
+		}, elementPrev)
+		if err != nil {
+			return nil, fmt.Errorf("%s %d: %s", name, index, err)
+		}
+		loops[index] = loop
+	}
+	return loops, nil
+}
+
+// Follows next from the start edge until it returns to the start, expecting
+// to visit exactly count edges. next must only be given edges in range.
+// With prev given, a walk reaching NoEdge is an open fan, which is returned
+// from the edge where prev gives NoEdge to the one where next does.
+func edgeLoop(start int32, count int, next, prev func(int32) (int32, error)) ([]int32, error) {
+	if prev != nil {
+		var first int32 = start
+		for steps := 0; steps < count; steps++ {
+			prevIndex, err := prev(first)
+			if err != nil {
+				return nil, fmt.Errorf("edge %d breaks the loop: %s", first, err)
+			}
+			if prevIndex == start {
+				// a closed loop, keep the start
+				break
+			}
+			if prevIndex == NoEdge {
+				start = first
+				break
+			}
+			first = prevIndex
+		}
+	}
+	var edges []int32 = make([]int32, 0, count)
+	var current int32 = start
+	for {
+		edges = append(edges, current)
+		nextIndex, err := next(current)
+		if err != nil {
+			return nil, fmt.Errorf("edge %d breaks the loop: %s", current, err)
+		}
+		if nextIndex == start || (nextIndex == NoEdge && prev != nil) {
+			break
+		}
+		if nextIndex == NoEdge {
+			return nil, fmt.Errorf("edge %d reaches the boundary", current)
+		}
+		if len(edges) >= count {
+			return nil, fmt.Errorf("loop from edge %d does not close after %d edges", start, count)
+		}
+		current = nextIndex
+	}
+	if len(edges) != count {
+		return nil, fmt.Errorf("loop from edge %d has %d edges, expected %d", start, len(edges), count)
+	}
+	return edges, nil
+}
+
+// Sets PrevA and PrevB of every edge from NextA and NextB of the others, and
+// PrevB to NoEdge on boundary edges.
+// Returns an error if a next index is out of range or doesn't share the face.
+func (theGrid WingedGrid) setPrevFromNext() error {
+	for index, edge := range theGrid.Edges {
+		var sides = [2][2]int32{{edge.FaceA, edge.NextA}, {edge.FaceB, edge.NextB}}
+		if edge.FaceB == NoFace {
+			theGrid.Edges[index].PrevB = NoEdge
+		}
+		for _, side := range sides {
+			var faceIndex, nextIndex int32 = side[0], side[1]
+			if faceIndex == NoFace {
+				continue
+			}
+			if !indexInRange(nextIndex, len(theGrid.Edges)) {
+				return fmt.Errorf("Edge %d references edge %d out of range", index, nextIndex)
+			}
+			var nextEdge *WingedEdge = &theGrid.Edges[nextIndex]
+			if nextEdge.FaceA == faceIndex {
+				nextEdge.PrevA = int32(index)
+			} else if nextEdge.FaceB == faceIndex {
+				nextEdge.PrevB = int32(index)
+			} else {
+				return fmt.Errorf("Edge %d has next edge %d not on face %d", index, nextIndex, faceIndex)
+			}
+		}
+	}
+	return nil
+}
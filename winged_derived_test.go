@@ -0,0 +1,96 @@
+package wingedGrid
+
+import (
+	"testing"
+)
+
+func TestRebuildDerivedRepairsDamage(t *testing.T) {
+	base, _ := BaseIcosahedron()
+	grid, err := base.SubdivideTriangles(2)
+	if err != nil {
+		t.Fatalf("Failed to subdivide base icosahedron: %s", err)
+	}
+	dual, _ := grid.CreateDual()
+	for _, theGrid := range []WingedGrid{grid, dual} {
+		// wipe some lists, scramble others
+		theGrid.Faces[0].Edges = nil
+		theGrid.Faces[3].Edges[0], theGrid.Faces[3].Edges[1] = theGrid.Faces[3].Edges[1], theGrid.Faces[3].Edges[0]
+		theGrid.Vertices[1].Edges = []int32{-1}
+		theGrid.Vertices[20].Edges = theGrid.Vertices[20].Edges[:2]
+		if theGrid.Validate(ValidateOptions{}).Valid() {
+			t.Fatal("Expected damaged grid to be invalid")
+		}
+
+		err = theGrid.RebuildDerived()
+		if err != nil {
+			t.Fatalf("Failed to rebuild: %s", err)
+		}
+		report := theGrid.Validate(ValidateOptions{Spherical: true})
+		if !report.Valid() {
+			t.Errorf("Expected rebuilt grid to be valid, got: %s", report.Err())
+		}
+	}
+}
+
+func TestRebuildDerivedMatchesCounts(t *testing.T) {
+	grid, _ := BaseIcosahedron()
+	err := grid.RebuildDerived()
+	if err != nil {
+		t.Fatalf("Failed to rebuild: %s", err)
+	}
+	for index, face := range grid.Faces {
+		if len(face.Edges) != 3 {
+			t.Errorf("Face %d has %d edges, expected 3", index, len(face.Edges))
+		}
+	}
+	for index, vertex := range grid.Vertices {
+		if len(vertex.Edges) != 5 {
+			t.Errorf("Vertex %d has %d edges, expected 5", index, len(vertex.Edges))
+		}
+	}
+}
+
+func TestRebuildDerivedRejectsBrokenEdges(t *testing.T) {
+	grid, _ := BaseIcosahedron()
+	grid.Edges[4].FaceB = 20
+	if err := grid.RebuildDerived(); err == nil {
+		t.Error("Expected an error for a face out of range")
+	}
+
+	grid, _ = BaseIcosahedron()
+	// short circuit face 1, so walking it never returns to the start
+	grid.Edges[0].NextB = 5
+	grid.Edges[5].NextA = 5
+	if err := grid.RebuildDerived(); err == nil {
+		t.Error("Expected an error for a face that doesn't form a loop")
+	}
+	if len(grid.Faces[1].Edges) != 3 {
+		t.Error("Failed rebuild should leave the grid unchanged")
+	}
+}
+
+func TestRebuildDerivedOpenGrid(t *testing.T) {
+	base, _ := BaseIcosahedron()
+	grid, err := base.SubdivideTriangles(2)
+	if err != nil {
+		t.Fatalf("Failed to subdivide base icosahedron: %s", err)
+	}
+	patch := northernPatch(t, grid)
+	for index := range patch.Vertices {
+		// rotate the fans so they no longer start at the boundary
+		var edges []int32 = patch.Vertices[index].Edges
+		patch.Vertices[index].Edges = append(edges[1:len(edges):len(edges)], edges[0])
+	}
+	if patch.Validate(ValidateOptions{}).Valid() {
+		t.Fatal("Expected damaged patch to be invalid")
+	}
+
+	err = patch.RebuildDerived()
+	if err != nil {
+		t.Fatalf("Failed to rebuild: %s", err)
+	}
+	report := patch.Validate(ValidateOptions{Spherical: true})
+	if !report.Valid() {
+		t.Errorf("Expected rebuilt patch to be valid, got: %s", report.Err())
+	}
+}
@@ -1,6 +1,19 @@
 package wingedGrid
 
+import "fmt"
+
+// Creates the dual grid: face i of the dual surrounds vertex i and vertex i of
+// the dual is at the center of face i. For a closed grid edge i of the dual
+// crosses edge i. A grid with a boundary gets a dual with a boundary, see
+// createBoundedDual, whose edges are numbered afresh as by
+// NewGridFromPolygons, so dual edge i generally doesn't cross edge i.
 func (startGrid WingedGrid) CreateDual() (WingedGrid, error) {
+	for _, edge := range startGrid.Edges {
+		if edge.IsBoundary() {
+			return startGrid.createBoundedDual()
+		}
+	}
+
 	var dualGrid WingedGrid
 	// same number of edges
 	dualGrid.Edges = make([]WingedEdge, len(startGrid.Edges))
@@ -87,3 +100,58 @@ func (startGrid WingedGrid) CreateDual() (WingedGrid, error) {
 
 	return dualGrid, nil
 }
+
+// Creates the dual of a grid with a boundary. The face around a boundary
+// vertex is closed by the midpoints of its two boundary edges and the vertex
+// itself, which are added as dual vertices after the face centers: first one
+// per boundary edge in edge order, then one per boundary vertex in vertex order.
+// The dual's edges are numbered by NewGridFromPolygons rather than matching the
+// edges they cross.
+func (startGrid WingedGrid) createBoundedDual() (WingedGrid, error) {
+	var coords [][3]float64 = make([][3]float64, len(startGrid.Faces))
+	var err error
+	for index := range startGrid.Faces {
+		coords[index], err = startGrid.FaceCenter(int32(index))
+		if err != nil {
+			return WingedGrid{}, err
+		}
+	}
+
+	var midpoints map[int32]int32 = make(map[int32]int32)
+	for index, edge := range startGrid.Edges {
+		if !edge.IsBoundary() {
+			continue
+		}
+		var first, second [3]float64 = startGrid.Vertices[edge.FirstVertexA].Coords, startGrid.Vertices[edge.FirstVertexB].Coords
+		midpoints[int32(index)] = int32(len(coords))
+		coords = append(coords, [3]float64{(first[0] + second[0]) / 2, (first[1] + second[1]) / 2, (first[2] + second[2]) / 2})
+	}
+
+	var faces [][]int32 = make([][]int32, len(startGrid.Vertices))
+	for index, vertex := range startGrid.Vertices {
+		if len(vertex.Edges) == 0 {
+			return WingedGrid{}, fmt.Errorf("Vertex %d has no edges", index)
+		}
+		var face []int32
+		for _, edgeIndex := range vertex.Edges {
+			// the face on the side where the edge starts at this vertex
+			var edge WingedEdge = startGrid.Edges[edgeIndex]
+			var faceIndex int32 = edge.FaceB
+			if edge.FirstVertexA == int32(index) {
+				faceIndex = edge.FaceA
+			}
+			if faceIndex != NoFace {
+				face = append(face, faceIndex)
+			}
+		}
+		var last int32 = vertex.Edges[len(vertex.Edges)-1]
+		if startGrid.Edges[last].IsBoundary() && len(face) < len(vertex.Edges) {
+			var first int32 = vertex.Edges[0]
+			face = append(face, midpoints[last], int32(len(coords)), midpoints[first])
+			coords = append(coords, vertex.Coords)
+		}
+		faces[index] = face
+	}
+
+	return NewGridFromPolygons(coords, faces)
+}
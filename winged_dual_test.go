@@ -80,4 +80,50 @@ func TestDualFaceOrientation(t *testing.T) {
             t.Errorf("Incorrect orientation: center and normal not parellel for face: %d", index)
         }
     }//*/
-}
\ No newline at end of file
+}
+
+func TestDualOfOpenGrid(t *testing.T) {
+	base, _ := BaseIcosahedron()
+	subdivided, err := base.SubdivideTriangles(2)
+	if err != nil {
+		t.Fatalf("Failed to subdivide base icosahedron: %s", err)
+	}
+	patch := northernPatch(t, subdivided)
+	dual, err := patch.CreateDual()
+	if err != nil {
+		t.Fatalf("Error creating dual: %s", err)
+	}
+	report := dual.Validate(ValidateOptions{Spherical: true})
+	if !report.Valid() {
+		t.Fatalf("Dual invalid: %s", report.Err())
+	}
+
+	var boundaryEdges, dualBoundaryEdges int
+	for _, edge := range patch.Edges {
+		if edge.IsBoundary() {
+			boundaryEdges++
+		}
+	}
+	for _, edge := range dual.Edges {
+		if edge.IsBoundary() {
+			dualBoundaryEdges++
+		}
+	}
+	// a face center per face, and per boundary edge its midpoint and a corner
+	if len(dual.Vertices) != len(patch.Faces)+2*boundaryEdges {
+		t.Errorf("Expected %d vertices, got %d", len(patch.Faces)+2*boundaryEdges, len(dual.Vertices))
+	}
+	if len(dual.Faces) != len(patch.Vertices) {
+		t.Errorf("Expected %d faces, got %d", len(patch.Vertices), len(dual.Faces))
+	}
+	// each boundary edge becomes two, halves of the original boundary
+	if dualBoundaryEdges != 2*boundaryEdges {
+		t.Errorf("Expected %d boundary edges, got %d", 2*boundaryEdges, dualBoundaryEdges)
+	}
+	for index := range patch.Faces {
+		center, _ := patch.FaceCenter(int32(index))
+		if dual.Vertices[index].Coords != center {
+			t.Errorf("Dual vertex %d is not at the center of face %d", index, index)
+		}
+	}
+}
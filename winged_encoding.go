@@ -0,0 +1,394 @@
+package wingedGrid
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// Binary wire format for sending a WingedGrid over a network.
+//
+// All values are little-endian. The header is
+//   magic      [4]byte "WGRD"
+//   version    uint8
+//   flags      uint8
+//   reserved   uint16
+//   faceCount, edgeCount, vertexCount uint32
+// followed by the faces, each a uint16 edge count and int32 edge indices,
+// then the edges, eight int32 each in struct order, then the vertices, each
+// three coordinates (float64, or float32 with wireFlagFloat32) and a uint16
+// edge count with int32 edge indices.
+//
+// With wireFlagMinimal only the primary information is sent: no faces, the
+// edges as FirstVertexA, FirstVertexB, FaceA, FaceB, NextA, NextB, and the
+// vertices as coordinates alone. Everything else is rebuilt on decode.
+//
+// The vertex neighbor cache is not sent, it is rebuilt on decode.
+
+const (
+	wireMagic   = "WGRD"
+	wireVersion = 1
+
+	// coordinates are sent as float32
+	wireFlagFloat32 = 1 << 0
+	// only edges and coordinates are sent
+	wireFlagMinimal = 1 << 1
+
+	// most elements preallocated while decoding from a stream, so that a
+	// corrupt header can't force a huge allocation
+	wireMaxPrealloc = 1 << 16
+)
+
+// options for encoding a WingedGrid
+type WireOptions struct {
+	// send coordinates at single precision, halving their size
+	Float32Coords bool
+	// send only the edges and vertex coords, the face and vertex edge lists
+	// are rebuilt by the receiver, roughly halving the size
+	Minimal bool
+}
+
+// Encodes the grid at full precision, implementing encoding.BinaryMarshaler
+func (theGrid WingedGrid) MarshalBinary() ([]byte, error) {
+	var buffer bytes.Buffer
+	err := theGrid.EncodeWithOptions(&buffer, WireOptions{})
+	return buffer.Bytes(), err
+}
+
+// Decodes and validates a grid, implementing encoding.BinaryUnmarshaler
+func (theGrid *WingedGrid) UnmarshalBinary(data []byte) error {
+	var reader *bytes.Reader = bytes.NewReader(data)
+	err := theGrid.Decode(reader)
+	if err != nil {
+		return err
+	}
+	if reader.Len() != 0 {
+		return fmt.Errorf("%d trailing bytes after grid", reader.Len())
+	}
+	return nil
+}
+
+// Writes the grid at full precision to the writer
+func (theGrid WingedGrid) Encode(w io.Writer) error {
+	return theGrid.EncodeWithOptions(w, WireOptions{})
+}
+
+// Writes the grid to the writer
+func (theGrid WingedGrid) EncodeWithOptions(w io.Writer, options WireOptions) error {
+	var out *wireWriter = newWireWriter(w)
+	var flags uint8
+	if options.Float32Coords {
+		flags = flags | wireFlagFloat32
+	}
+	if options.Minimal {
+		flags = flags | wireFlagMinimal
+	}
+	out.writeHeader(flags, len(theGrid.Faces), len(theGrid.Edges), len(theGrid.Vertices))
+
+	if !options.Minimal {
+		for _, face := range theGrid.Faces {
+			out.writeIndexList(face.Edges)
+		}
+	}
+	for _, edge := range theGrid.Edges {
+		out.writeEdge(edge, options.Minimal)
+	}
+	for _, vertex := range theGrid.Vertices {
+		out.writeCoords(vertex.Coords, options.Float32Coords)
+		if !options.Minimal {
+			out.writeIndexList(vertex.Edges)
+		}
+	}
+	return out.flush()
+}
+
+// Reads a grid written by Encode from the reader, replacing the contents of
+// this grid. The decoded grid is validated before it is returned, allowing
+// zero length edges if it was sent with float32 coordinates, and on any error
+// this grid is left unchanged.
+// Nothing past the end of the grid is read, wrap unbuffered readers in a
+// bufio.Reader for speed.
+func (theGrid *WingedGrid) Decode(r io.Reader) error {
+	var in *wireReader = newWireReader(r)
+	flags, faceCount, edgeCount, vertexCount := in.readHeader()
+	if in.err != nil {
+		return in.err
+	}
+	var decoded WingedGrid
+	decoded.Faces = make([]WingedFace, 0, minInt(faceCount, wireMaxPrealloc))
+	decoded.Edges = make([]WingedEdge, 0, minInt(edgeCount, wireMaxPrealloc))
+	decoded.Vertices = make([]WingedVertex, 0, minInt(vertexCount, wireMaxPrealloc))
+
+	var minimal bool = flags&wireFlagMinimal != 0
+	for i := 0; i < faceCount && in.err == nil; i++ {
+		var face WingedFace
+		if !minimal {
+			face.Edges = in.readIndexList()
+		}
+		decoded.Faces = append(decoded.Faces, face)
+	}
+	for i := 0; i < edgeCount && in.err == nil; i++ {
+		decoded.Edges = append(decoded.Edges, in.readEdge(minimal))
+	}
+	for i := 0; i < vertexCount && in.err == nil; i++ {
+		var vertex WingedVertex
+		vertex.Coords = in.readCoords(flags&wireFlagFloat32 != 0)
+		if !minimal {
+			vertex.Edges = in.readIndexList()
+		}
+		decoded.Vertices = append(decoded.Vertices, vertex)
+	}
+	if in.err != nil {
+		return in.err
+	}
+
+	if minimal {
+		err := decoded.setPrevFromNext()
+		if err != nil {
+			return err
+		}
+		err = decoded.RebuildDerived()
+		if err != nil {
+			return err
+		}
+	}
+
+	// float32 rounding can collapse a short edge
+	var allowZeroLength bool = flags&wireFlagFloat32 != 0
+	err := decoded.Validate(ValidateOptions{AllowZeroLengthEdges: allowZeroLength, MaxViolations: 10}).Err()
+	if err != nil {
+		return err
+	}
+	decoded.fillVertexNeighbors()
+	*theGrid = decoded
+	return nil
+}
+
+// populates the vertex neighbor cache for every vertex
+func (theGrid WingedGrid) fillVertexNeighbors() {
+	for index := range theGrid.Vertices {
+		theGrid.NeighborsForVertex(int32(index))
+	}
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+/******************* Writer ********************/
+
+// buffers output and holds on to the first error, so encoding can run
+// straight through and check once at the end
+type wireWriter struct {
+	out     *bufio.Writer
+	scratch [8]byte
+	err     error
+}
+
+func newWireWriter(w io.Writer) *wireWriter {
+	return &wireWriter{out: bufio.NewWriter(w)}
+}
+
+func (w *wireWriter) write(data []byte) {
+	if w.err != nil {
+		return
+	}
+	_, w.err = w.out.Write(data)
+}
+
+func (w *wireWriter) writeUint16(value uint16) {
+	binary.LittleEndian.PutUint16(w.scratch[:2], value)
+	w.write(w.scratch[:2])
+}
+
+func (w *wireWriter) writeUint32(value uint32) {
+	binary.LittleEndian.PutUint32(w.scratch[:4], value)
+	w.write(w.scratch[:4])
+}
+
+func (w *wireWriter) writeInt32(value int32) {
+	w.writeUint32(uint32(value))
+}
+
+func (w *wireWriter) writeHeader(flags uint8, faceCount, edgeCount, vertexCount int) {
+	if faceCount > math.MaxInt32 || edgeCount > math.MaxInt32 || vertexCount > math.MaxInt32 {
+		w.setErr(errors.New("Grid too large to encode"))
+		return
+	}
+	w.write([]byte(wireMagic))
+	w.write([]byte{wireVersion, flags})
+	w.writeUint16(0)
+	w.writeUint32(uint32(faceCount))
+	w.writeUint32(uint32(edgeCount))
+	w.writeUint32(uint32(vertexCount))
+}
+
+func (w *wireWriter) writeIndexList(indices []int32) {
+	if len(indices) > math.MaxUint16 {
+		w.setErr(fmt.Errorf("Element with %d edges too large to encode", len(indices)))
+		return
+	}
+	w.writeUint16(uint16(len(indices)))
+	for _, index := range indices {
+		w.writeInt32(index)
+	}
+}
+
+// minimal edges leave out prev, which can be found from next
+func (w *wireWriter) writeEdge(edge WingedEdge, minimal bool) {
+	w.writeInt32(edge.FirstVertexA)
+	w.writeInt32(edge.FirstVertexB)
+	w.writeInt32(edge.FaceA)
+	w.writeInt32(edge.FaceB)
+	if !minimal {
+		w.writeInt32(edge.PrevA)
+	}
+	w.writeInt32(edge.NextA)
+	if !minimal {
+		w.writeInt32(edge.PrevB)
+	}
+	w.writeInt32(edge.NextB)
+}
+
+func (w *wireWriter) writeCoords(coords [3]float64, single bool) {
+	for _, value := range coords {
+		if single {
+			if math.Abs(value) > math.MaxFloat32 {
+				w.setErr(fmt.Errorf("Coordinate %v out of float32 range", value))
+				return
+			}
+			w.writeUint32(math.Float32bits(float32(value)))
+		} else {
+			binary.LittleEndian.PutUint64(w.scratch[:], math.Float64bits(value))
+			w.write(w.scratch[:])
+		}
+	}
+}
+
+func (w *wireWriter) setErr(err error) {
+	if w.err == nil {
+		w.err = err
+	}
+}
+
+func (w *wireWriter) flush() error {
+	if w.err != nil {
+		return w.err
+	}
+	return w.out.Flush()
+}
+
+/******************* Reader ********************/
+
+// reads values, holding on to the first error
+type wireReader struct {
+	in      io.Reader
+	scratch [8]byte
+	err     error
+}
+
+func newWireReader(r io.Reader) *wireReader {
+	return &wireReader{in: r}
+}
+
+func (r *wireReader) read(size int) []byte {
+	if r.err != nil {
+		return r.scratch[:size]
+	}
+	_, err := io.ReadFull(r.in, r.scratch[:size])
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	r.err = err
+	return r.scratch[:size]
+}
+
+func (r *wireReader) readUint16() uint16 {
+	return binary.LittleEndian.Uint16(r.read(2))
+}
+
+func (r *wireReader) readUint32() uint32 {
+	return binary.LittleEndian.Uint32(r.read(4))
+}
+
+func (r *wireReader) readInt32() int32 {
+	return int32(r.readUint32())
+}
+
+// Returns the flags and element counts, checking the magic and version
+func (r *wireReader) readHeader() (uint8, int, int, int) {
+	var magic []byte = r.read(4)
+	if r.err == nil && string(magic) != wireMagic {
+		r.err = errors.New("Not a WingedGrid encoding")
+	}
+	var versionAndFlags []byte = r.read(2)
+	var version, flags uint8 = versionAndFlags[0], versionAndFlags[1]
+	if r.err == nil && version != wireVersion {
+		r.err = fmt.Errorf("Unsupported WingedGrid encoding version %d", version)
+	}
+	if r.err == nil && flags&^(wireFlagFloat32|wireFlagMinimal) != 0 {
+		r.err = fmt.Errorf("Unknown WingedGrid encoding flags %#x", flags)
+	}
+	var reserved uint16 = r.readUint16()
+	if r.err == nil && reserved != 0 {
+		r.err = fmt.Errorf("Reserved WingedGrid header field is %d, expected 0", reserved)
+	}
+	var counts [3]int
+	for i := range counts {
+		var count uint32 = r.readUint32()
+		if r.err == nil && count > math.MaxInt32 {
+			r.err = fmt.Errorf("Element count %d too large", count)
+		}
+		counts[i] = int(count)
+	}
+	return flags, counts[0], counts[1], counts[2]
+}
+
+func (r *wireReader) readIndexList() []int32 {
+	var count uint16 = r.readUint16()
+	if r.err != nil {
+		return nil
+	}
+	var indices []int32 = make([]int32, count)
+	for i := range indices {
+		indices[i] = r.readInt32()
+	}
+	return indices
+}
+
+// prev is left as -1 for minimal edges
+func (r *wireReader) readEdge(minimal bool) WingedEdge {
+	var edge WingedEdge = WingedEdge{PrevA: -1, PrevB: -1}
+	edge.FirstVertexA = r.readInt32()
+	edge.FirstVertexB = r.readInt32()
+	edge.FaceA = r.readInt32()
+	edge.FaceB = r.readInt32()
+	if !minimal {
+		edge.PrevA = r.readInt32()
+	}
+	edge.NextA = r.readInt32()
+	if !minimal {
+		edge.PrevB = r.readInt32()
+	}
+	edge.NextB = r.readInt32()
+	return edge
+}
+
+func (r *wireReader) readCoords(single bool) [3]float64 {
+	var coords [3]float64
+	for i := range coords {
+		if single {
+			coords[i] = float64(math.Float32frombits(r.readUint32()))
+		} else {
+			coords[i] = math.Float64frombits(binary.LittleEndian.Uint64(r.read(8)))
+		}
+	}
+	return coords
+}
@@ -0,0 +1,240 @@
+package wingedGrid
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+)
+
+// compares everything but the neighbor cache
+func gridsEqual(t *testing.T, expected, got WingedGrid, coordTolerance float64) {
+	if len(expected.Faces) != len(got.Faces) || len(expected.Edges) != len(got.Edges) || len(expected.Vertices) != len(got.Vertices) {
+		t.Fatalf("Counts differ. Expected: %d/%d/%d, Got: %d/%d/%d", len(expected.Faces), len(expected.Edges), len(expected.Vertices), len(got.Faces), len(got.Edges), len(got.Vertices))
+	}
+	for index, face := range expected.Faces {
+		if !int32SlicesEqual(face.Edges, got.Faces[index].Edges) {
+			t.Fatalf("Face %d differs. Expected: %v, Got: %v", index, face.Edges, got.Faces[index].Edges)
+		}
+	}
+	for index, edge := range expected.Edges {
+		if edge != got.Edges[index] {
+			t.Fatalf("Edge %d differs. Expected: %v, Got: %v", index, edge, got.Edges[index])
+		}
+	}
+	for index, vertex := range expected.Vertices {
+		if !int32SlicesEqual(vertex.Edges, got.Vertices[index].Edges) {
+			t.Fatalf("Vertex %d edges differ. Expected: %v, Got: %v", index, vertex.Edges, got.Vertices[index].Edges)
+		}
+		if distanceBetween3Points(vertex.Coords, got.Vertices[index].Coords) > coordTolerance {
+			t.Fatalf("Vertex %d coords differ. Expected: %v, Got: %v", index, vertex.Coords, got.Vertices[index].Coords)
+		}
+	}
+}
+
+func int32SlicesEqual(a, b []int32) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestBinaryRoundTrip(t *testing.T) {
+	base, _ := BaseIcosahedron()
+	grid, err := base.SubdivideTriangles(3)
+	if err != nil {
+		t.Fatalf("Failed to subdivide base icosahedron: %s", err)
+	}
+	data, err := grid.MarshalBinary()
+	if err != nil {
+		t.Fatalf("Failed to marshal grid: %s", err)
+	}
+	var decoded WingedGrid
+	err = decoded.UnmarshalBinary(data)
+	if err != nil {
+		t.Fatalf("Failed to unmarshal grid: %s", err)
+	}
+	gridsEqual(t, grid, decoded, 0)
+
+	// neighbor cache should come back filled
+	for index, vertex := range decoded.Vertices {
+		if len(vertex.vertexNeighbors) != len(vertex.Edges) {
+			t.Fatalf("Neighbor cache not rebuilt for vertex %d", index)
+		}
+	}
+}
+
+func TestBinaryStreamFloat32(t *testing.T) {
+	grid, _ := BaseIcosahedron()
+	dual, _ := grid.CreateDual()
+	var buffer bytes.Buffer
+	// two grids back to back in the same stream
+	if err := grid.EncodeWithOptions(&buffer, WireOptions{Float32Coords: true}); err != nil {
+		t.Fatalf("Failed to encode grid: %s", err)
+	}
+	singleSize := buffer.Len()
+	if err := dual.Encode(&buffer); err != nil {
+		t.Fatalf("Failed to encode dual: %s", err)
+	}
+
+	var first, second WingedGrid
+	if err := first.Decode(&buffer); err != nil {
+		t.Fatalf("Failed to decode grid: %s", err)
+	}
+	if err := second.Decode(&buffer); err != nil {
+		t.Fatalf("Failed to decode dual: %s", err)
+	}
+	gridsEqual(t, grid, first, 1e-6)
+	gridsEqual(t, dual, second, 0)
+
+	var full bytes.Buffer
+	grid.Encode(&full)
+	if full.Len()-singleSize != 12*len(grid.Vertices) {
+		t.Errorf("Expected float32 coords to save %d bytes, saved %d", 12*len(grid.Vertices), full.Len()-singleSize)
+	}
+}
+
+func TestBinaryFloat32ShortEdge(t *testing.T) {
+	// a tetrahedron with one face split around a point very near a corner,
+	// which float32 rounds onto the corner
+	var a, b, c, d [3]float64 = [3]float64{1, 1, 1}, [3]float64{1, -1, -1}, [3]float64{-1, 1, -1}, [3]float64{-1, -1, 1}
+	var e [3]float64 = vectorAdd(a, vectorScale(vectorSub(vectorAdd(b, c), vectorScale(a, 2)), 1e-9))
+	grid, err := NewGridFromPolygons([][3]float64{a, b, c, d, e}, [][]int32{{0, 2, 4}, {2, 1, 4}, {1, 0, 4}, {0, 3, 2}, {0, 1, 3}, {1, 2, 3}})
+	if err != nil {
+		t.Fatalf("Failed to build grid: %s", err)
+	}
+	if report := grid.Validate(ValidateOptions{}); !report.Valid() {
+		t.Fatalf("Grid invalid before encoding: %s", report.Err())
+	}
+	var buffer bytes.Buffer
+	if err := grid.EncodeWithOptions(&buffer, WireOptions{Float32Coords: true}); err != nil {
+		t.Fatalf("Failed to encode: %s", err)
+	}
+	var decoded WingedGrid
+	if err := decoded.Decode(&buffer); err != nil {
+		t.Fatalf("Failed to decode: %s", err)
+	}
+	if decoded.Vertices[4].Coords != decoded.Vertices[0].Coords {
+		t.Errorf("Expected the short edge to collapse")
+	}
+}
+
+func TestBinaryFloat32RejectsNonFinite(t *testing.T) {
+	for _, options := range []WireOptions{{Float32Coords: true}, {Float32Coords: true, Minimal: true}} {
+		grid, _ := BaseIcosahedron()
+		grid.Vertices[3].Coords[1] = math.NaN()
+		var buffer bytes.Buffer
+		if err := grid.EncodeWithOptions(&buffer, options); err != nil {
+			t.Fatalf("Failed to encode: %s", err)
+		}
+		var decoded WingedGrid
+		if err := decoded.Decode(&buffer); err == nil {
+			t.Errorf("Expected an error decoding NaN coordinates with %+v", options)
+		}
+
+		grid.Vertices[3].Coords[1] = 1e39
+		buffer.Reset()
+		if err := grid.EncodeWithOptions(&buffer, options); err == nil {
+			t.Errorf("Expected an error encoding a coordinate out of float32 range with %+v", options)
+		}
+	}
+}
+
+func TestBinaryRejectsCorruptData(t *testing.T) {
+	grid, _ := BaseIcosahedron()
+	data, _ := grid.MarshalBinary()
+
+	var decoded WingedGrid
+	if err := decoded.UnmarshalBinary(data[:len(data)-3]); err == nil {
+		t.Error("Expected an error for truncated data")
+	}
+
+	corrupt := append([]byte(nil), data...)
+	corrupt[0] = 'X'
+	if err := decoded.UnmarshalBinary(corrupt); err == nil {
+		t.Error("Expected an error for a bad magic")
+	}
+
+	corrupt = append([]byte(nil), data...)
+	corrupt[4] = wireVersion + 1
+	if err := decoded.UnmarshalBinary(corrupt); err == nil {
+		t.Error("Expected an error for an unknown version")
+	}
+
+	corrupt = append([]byte(nil), data...)
+	corrupt[5] = 1 << 7
+	if err := decoded.UnmarshalBinary(corrupt); err == nil {
+		t.Error("Expected an error for an unknown flag")
+	}
+
+	corrupt = append([]byte(nil), data...)
+	corrupt[6] = 1
+	if err := decoded.UnmarshalBinary(corrupt); err == nil {
+		t.Error("Expected an error for a nonzero reserved field")
+	}
+
+	// point the first edge of the first face past the end of the edges
+	corrupt = append([]byte(nil), data...)
+	binary.LittleEndian.PutUint32(corrupt[20+2:], 5000)
+	if err := decoded.UnmarshalBinary(corrupt); err == nil {
+		t.Error("Expected an error for an out of range index")
+	}
+
+	// a header claiming a huge grid shouldn't allocate it up front
+	corrupt = append([]byte(nil), data[:20]...)
+	binary.LittleEndian.PutUint32(corrupt[8:], 1<<30)
+	if err := decoded.UnmarshalBinary(corrupt); err == nil {
+		t.Error("Expected an error for a truncated huge grid")
+	}
+
+	if len(decoded.Faces) != 0 {
+		t.Error("Failed decodes should leave the grid unchanged")
+	}
+}
+
+func TestBinaryMinimalRoundTrip(t *testing.T) {
+	base, _ := BaseIcosahedron()
+	grid, _ := base.SubdivideTriangles(5)
+	dual, _ := grid.CreateDual()
+	for _, theGrid := range []WingedGrid{grid, dual} {
+		var full, minimal bytes.Buffer
+		theGrid.Encode(&full)
+		err := theGrid.EncodeWithOptions(&minimal, WireOptions{Minimal: true})
+		if err != nil {
+			t.Fatalf("Failed to encode minimal grid: %s", err)
+		}
+		// roughly half, six of the eight edge fields plus coords
+		if minimal.Len()*3 > full.Len()*2 {
+			t.Errorf("Minimal encoding is %d bytes, full is %d", minimal.Len(), full.Len())
+		}
+
+		var decoded WingedGrid
+		err = decoded.Decode(&minimal)
+		if err != nil {
+			t.Fatalf("Failed to decode minimal grid: %s", err)
+		}
+		// prev is rebuilt exactly, but face and vertex lists may start at a
+		// different edge
+		for index, edge := range theGrid.Edges {
+			if edge != decoded.Edges[index] {
+				t.Fatalf("Edge %d differs. Expected: %v, Got: %v", index, edge, decoded.Edges[index])
+			}
+		}
+		for index, vertex := range theGrid.Vertices {
+			if vertex.Coords != decoded.Vertices[index].Coords {
+				t.Fatalf("Vertex %d coords differ", index)
+			}
+		}
+		if len(decoded.Faces) != len(theGrid.Faces) {
+			t.Fatalf("Expected %d faces, got %d", len(theGrid.Faces), len(decoded.Faces))
+		}
+		if !decoded.Validate(ValidateOptions{Spherical: true}).Valid() {
+			t.Error("Decoded minimal grid is not valid")
+		}
+	}
+}
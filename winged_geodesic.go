@@ -0,0 +1,323 @@
+package wingedGrid
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Geodesic subdivision of triangle faces along a skewed triangular lattice.
+//
+// Each old face is laid over the lattice with its clockwise vertices at the
+// lattice points (0, 0), (m, n) and (-n, m+n), in axial coordinates along two
+// lattice directions 60 degrees apart, so every old face covers
+// T = m*m + m*n + n*n small triangles. Neighboring faces are the same lattice
+// unfolded across their shared edge, which is a 180 degree turn about its
+// midpoint, so small triangles crossing an old edge can be found in either
+// face. A small triangle whose center lies on an old edge belongs to FaceA of
+// the edge.
+
+// a point of the triangular lattice in axial coordinates
+type latticePoint [2]int
+
+func (point latticePoint) minus(other latticePoint) latticePoint {
+	return latticePoint{point[0] - other[0], point[1] - other[1]}
+}
+
+func (point latticePoint) plus(other latticePoint) latticePoint {
+	return latticePoint{point[0] + other[0], point[1] + other[1]}
+}
+
+// turns the point about the origin counter-clockwise by steps of 60 degrees
+func (point latticePoint) rotated(steps int) latticePoint {
+	for i := 0; i < ((steps%6)+6)%6; i++ {
+		point = latticePoint{-point[1], point[0] + point[1]}
+	}
+	return point
+}
+
+// positive when other is counter-clockwise from point
+func latticeCross(point, other latticePoint) int {
+	return point[0]*other[1] - point[1]*other[0]
+}
+
+// Returns the index of a in the list, or -1 if it isn't there
+func int32IndexInSlice(a int32, list []int32) int {
+	for index, b := range list {
+		if b == a {
+			return index
+		}
+	}
+	return -1
+}
+
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
+
+// the lattice shared by every face of a geodesic subdivision
+type geodesicLattice struct {
+	corners [3]latticePoint
+	// lattice points per edge, including one corner
+	edgeSteps int
+	// area of a face, in small triangles
+	area int
+	// points strictly inside a face, and their index among them
+	interior      []latticePoint
+	interiorIndex map[latticePoint]int
+	// small triangles strictly inside a face
+	inside [][3]latticePoint
+	// small triangles split by each edge of a face, seen from that face
+	split [3][][3]latticePoint
+}
+
+func newGeodesicLattice(m, n int) geodesicLattice {
+	var lattice geodesicLattice = geodesicLattice{
+		corners:       [3]latticePoint{{0, 0}, {m, n}, {-n, m + n}},
+		edgeSteps:     gcd(m, n),
+		area:          m*m + m*n + n*n,
+		interiorIndex: make(map[latticePoint]int),
+	}
+	var low, high latticePoint = latticePoint{-n - 1, -1}, latticePoint{m + 1, m + n + 1}
+	for b := low[1]; b <= high[1]; b++ {
+		for a := low[0]; a <= high[0]; a++ {
+			var point latticePoint = latticePoint{a, b}
+			if sides := lattice.sides(point, 1); sides[0] > 0 && sides[1] > 0 && sides[2] > 0 {
+				lattice.interiorIndex[point] = len(lattice.interior)
+				lattice.interior = append(lattice.interior, point)
+			}
+			// the upward and downward small triangles to the upper right of
+			// the point, counter-clockwise like the face
+			for _, triangle := range [2][3]latticePoint{
+				{point, {a + 1, b}, {a, b + 1}},
+				{{a + 1, b}, {a + 1, b + 1}, {a, b + 1}},
+			} {
+				// three times the center, to stay on the lattice
+				var center latticePoint = triangle[0].plus(triangle[1]).plus(triangle[2])
+				var sides [3]int = lattice.sides(center, 3)
+				if sides[0] > 0 && sides[1] > 0 && sides[2] > 0 {
+					lattice.inside = append(lattice.inside, triangle)
+				}
+				for k := 0; k < 3; k++ {
+					if sides[k] == 0 && sides[(k+1)%3] > 0 && sides[(k+2)%3] > 0 {
+						lattice.split[k] = append(lattice.split[k], triangle)
+					}
+				}
+			}
+		}
+	}
+	return lattice
+}
+
+// Returns for each edge of a face how far the point, in units of scale
+// lattice steps, is to its left, positive inside the face
+func (lattice geodesicLattice) sides(point latticePoint, scale int) [3]int {
+	var sides [3]int
+	for k := 0; k < 3; k++ {
+		var start, end latticePoint = lattice.corners[k], lattice.corners[(k+1)%3]
+		var edge latticePoint = end.minus(start)
+		sides[k] = latticeCross(edge, point.minus(latticePoint{start[0] * scale, start[1] * scale}))
+	}
+	return sides
+}
+
+// Subdivides a grid of triangle faces along the (m, n) breakdown vector,
+// giving m*m + m*n + n*n faces for each old face. (m, 0) is class I, with the
+// same topology as SubdivideTriangles(m-1), (m, m) is class II and other
+// values are class III, where new edges cross the old ones. Any orientable
+// triangulated surface can be subdivided, but only class I keeps new edges
+// off a boundary, other classes return an error wrapping ErrOpenSurface.
+//
+// New vertices are placed on each old face by their barycentric coordinates,
+// then scaled to the radius interpolated in the same way from the old
+// vertices, so a spherical grid stays spherical. Vertices are numbered as
+// described for geodesicPolygons, faces by the old face they are centered on.
+func (oldGrid WingedGrid) SubdivideGeodesic(m, n int32) (WingedGrid, error) {
+	coords, faces, err := oldGrid.geodesicPolygons(m, n)
+	if err != nil {
+		return WingedGrid{}, err
+	}
+	return NewGridFromPolygons(coords, faces)
+}
+
+// Subdivides a grid of triangle faces along the (m, n) lattice, returning
+// the new vertices' coords and faces as vertex lists. Vertices are numbered
+// as in SubdivideTriangles: the old vertices, then the gcd(m, n)-1 vertices
+// along each old edge from FirstVertexA, then the interior vertices of each
+// old face.
+func (oldGrid WingedGrid) geodesicPolygons(m, n int32) ([][3]float64, [][]int32, error) {
+	if m < 0 || n < 0 || m+n < 1 {
+		return nil, nil, errors.New("Invalid breakdown vector")
+	}
+	faceVertices, err := oldGrid.faceVertexLists()
+	if err != nil {
+		return nil, nil, err
+	}
+	for faceIndex, vertices := range faceVertices {
+		if len(vertices) != 3 {
+			return nil, nil, fmt.Errorf("Face %d has %d edges, expected a triangle", faceIndex, len(vertices))
+		}
+	}
+	// count before building the lattice, which has about T points. T is at
+	// least 3(m+n)^2/4, so a larger m+n is too many and T fits in int64.
+	if int64(m)+int64(n) >= 1<<16 {
+		return nil, nil, errors.New("Too many elements for int32 indices")
+	}
+	var area int64 = int64(m)*int64(m) + int64(m)*int64(n) + int64(n)*int64(n)
+	var edgeSteps int64 = int64(gcd(int(m), int(n)))
+	// Pick's theorem on the triangular lattice, T = 2*interior + 3*edgeSteps - 2
+	var interiorCount int64 = (area - 3*edgeSteps + 2) / 2
+	var vertexCount int64 = int64(len(oldGrid.Vertices)) + int64(len(oldGrid.Edges))*(edgeSteps-1) + int64(len(oldGrid.Faces))*interiorCount
+	if area > maxGridElements || vertexCount > maxGridElements || int64(len(oldGrid.Faces))*area > maxGridElements {
+		return nil, nil, errors.New("Too many elements for int32 indices")
+	}
+	var lattice geodesicLattice = newGeodesicLattice(int(m), int(n))
+	var edgeOffset int = len(oldGrid.Vertices)
+	var faceOffset int = edgeOffset + len(oldGrid.Edges)*(lattice.edgeSteps-1)
+
+	// Returns the index of the vertex at a lattice point of a face, moving
+	// to the neighboring face when the point is outside it
+	var vertexAt = func(faceIndex int, point latticePoint) (int32, error) {
+		// a point of a small triangle is never more than one face away
+		for moves := 0; moves < 2; moves++ {
+			var sides [3]int = lattice.sides(point, 1)
+			var outside int = -1
+			for k := 0; k < 3; k++ {
+				if sides[k] < 0 {
+					outside = k
+				}
+			}
+			if outside < 0 {
+				break
+			}
+			var edgeIndex int32 = oldGrid.Faces[faceIndex].Edges[outside]
+			neighbor, err := oldGrid.Edges[edgeIndex].AdjacentForFace(int32(faceIndex))
+			if err != nil {
+				return -1, err
+			}
+			if neighbor == NoFace {
+				return -1, fmt.Errorf("%w: the lattice crosses boundary edge %d", ErrOpenSurface, edgeIndex)
+			}
+			var neighborEdge int = int32IndexInSlice(edgeIndex, oldGrid.Faces[neighbor].Edges)
+			if neighborEdge < 0 {
+				return -1, fmt.Errorf("Edge %d not in face %d", edgeIndex, neighbor)
+			}
+			// the end of the edge in this face is its start in the neighbor
+			var end latticePoint = lattice.corners[(outside+1)%3]
+			point = point.minus(end).rotated(2*neighborEdge - 3 - 2*outside).plus(lattice.corners[neighborEdge])
+			faceIndex = int(neighbor)
+		}
+
+		var sides [3]int = lattice.sides(point, 1)
+		if index, ok := lattice.interiorIndex[point]; ok {
+			return int32(faceOffset + faceIndex*len(lattice.interior) + index), nil
+		}
+		for k := 0; k < 3; k++ {
+			if point == lattice.corners[k] {
+				return faceVertices[faceIndex][k], nil
+			}
+		}
+		for k := 0; k < 3; k++ {
+			if sides[k] != 0 || sides[(k+1)%3] < 0 || sides[(k+2)%3] < 0 {
+				continue
+			}
+			var step latticePoint = lattice.edgeStep(k)
+			var offset latticePoint = point.minus(lattice.corners[k])
+			var steps int
+			if step[0] != 0 {
+				steps = offset[0] / step[0]
+			} else {
+				steps = offset[1] / step[1]
+			}
+			var edgeIndex int32 = oldGrid.Faces[faceIndex].Edges[k]
+			if oldGrid.Edges[edgeIndex].FaceA != int32(faceIndex) {
+				steps = lattice.edgeSteps - steps
+			}
+			return int32(edgeOffset + int(edgeIndex)*(lattice.edgeSteps-1) + steps - 1), nil
+		}
+		return -1, fmt.Errorf("Lattice point %v not found near face %d", point, faceIndex)
+	}
+
+	var coords [][3]float64 = make([][3]float64, vertexCount)
+	for index, vertex := range oldGrid.Vertices {
+		coords[index] = vertex.Coords
+	}
+	var faceCorners = func(faceIndex int) [3][3]float64 {
+		var corners [3][3]float64
+		for k, vertexIndex := range faceVertices[faceIndex] {
+			corners[k] = oldGrid.Vertices[vertexIndex].Coords
+		}
+		return corners
+	}
+	for edgeIndex, edge := range oldGrid.Edges {
+		var faceIndex int = int(edge.FaceA)
+		var k int = int32IndexInSlice(int32(edgeIndex), oldGrid.Faces[faceIndex].Edges)
+		if k < 0 {
+			return nil, nil, fmt.Errorf("Edge %d not in face %d", edgeIndex, faceIndex)
+		}
+		var step latticePoint = lattice.edgeStep(k)
+		var corners [3][3]float64 = faceCorners(faceIndex)
+		for s := 1; s < lattice.edgeSteps; s++ {
+			var point latticePoint = lattice.corners[k].plus(latticePoint{step[0] * s, step[1] * s})
+			coords[edgeOffset+edgeIndex*(lattice.edgeSteps-1)+s-1] = lattice.position(corners, point)
+		}
+	}
+
+	var faces [][]int32 = make([][]int32, 0, len(oldGrid.Faces)*lattice.area)
+	for faceIndex := range oldGrid.Faces {
+		var corners [3][3]float64 = faceCorners(faceIndex)
+		for index, point := range lattice.interior {
+			coords[faceOffset+faceIndex*len(lattice.interior)+index] = lattice.position(corners, point)
+		}
+		var triangles [][3]latticePoint = lattice.inside
+		for k := 0; k < 3; k++ {
+			if oldGrid.Edges[oldGrid.Faces[faceIndex].Edges[k]].FaceA == int32(faceIndex) {
+				triangles = append(triangles[:len(triangles):len(triangles)], lattice.split[k]...)
+			}
+		}
+		for _, triangle := range triangles {
+			var face []int32 = make([]int32, 3)
+			for i, point := range triangle {
+				face[i], err = vertexAt(faceIndex, point)
+				if err != nil {
+					return nil, nil, err
+				}
+			}
+			faces = append(faces, face)
+		}
+	}
+	return coords, faces, nil
+}
+
+// the step between lattice points along edge k of a face
+func (lattice geodesicLattice) edgeStep(k int) latticePoint {
+	var step latticePoint = lattice.corners[(k+1)%3].minus(lattice.corners[k])
+	return latticePoint{step[0] / lattice.edgeSteps, step[1] / lattice.edgeSteps}
+}
+
+// Returns the position of a lattice point of a face from its barycentric
+// coordinates, scaled to the radius interpolated from the corner radii
+func (lattice geodesicLattice) position(corners [3][3]float64, point latticePoint) [3]float64 {
+	var sides [3]int = lattice.sides(point, 1)
+	// the weight of each corner is the side of the opposite edge
+	var weights [3]float64 = [3]float64{
+		float64(sides[1]) / float64(lattice.area),
+		float64(sides[2]) / float64(lattice.area),
+		float64(sides[0]) / float64(lattice.area),
+	}
+	var flat [3]float64
+	var radius float64
+	for k := 0; k < 3; k++ {
+		for i := 0; i < 3; i++ {
+			flat[i] += weights[k] * corners[k][i]
+		}
+		radius += weights[k] * vectorLength(corners[k])
+	}
+	var length float64 = vectorLength(flat)
+	if length == 0 {
+		return flat
+	}
+	return [3]float64{flat[0] * radius / length, flat[1] * radius / length, flat[2] * radius / length}
+}
@@ -0,0 +1,125 @@
+package wingedGrid
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSubdivideGeodesicIntegrity(t *testing.T) {
+	var bases = map[string]func() (WingedGrid, error){
+		"tetrahedron": BaseTetrahedron,
+		"octahedron":  BaseOctahedron,
+		"icosahedron": BaseIcosahedron,
+	}
+	for name, create := range bases {
+		base, err := create()
+		if err != nil {
+			t.Fatalf("Failed to create %s: %s", name, err)
+		}
+		var radius float64 = vectorLength(base.Vertices[0].Coords)
+		for _, breakdown := range [][2]int32{{1, 0}, {3, 0}, {1, 1}, {2, 2}, {2, 1}, {1, 3}, {4, 3}} {
+			var m, n int32 = breakdown[0], breakdown[1]
+			var T int = int(m*m + m*n + n*n)
+			grid, err := base.SubdivideGeodesic(m, n)
+			if err != nil {
+				t.Fatalf("%s (%d,%d): %s", name, m, n, err)
+			}
+			if len(grid.Faces) != T*len(base.Faces) || len(grid.Vertices) != T*len(base.Faces)/2+2 {
+				t.Errorf("%s (%d,%d) has %d faces and %d vertices", name, m, n, len(grid.Faces), len(grid.Vertices))
+			}
+			for index := range grid.Vertices {
+				correct, err := VertEdgesMatchEdgeOrder(grid, int32(index))
+				if err != nil || !correct {
+					t.Errorf("%s (%d,%d) vertex %d edges out of order: %v", name, m, n, index, err)
+				}
+				if math.Abs(vectorLength(grid.Vertices[index].Coords)-radius) > 1e-9 {
+					t.Errorf("%s (%d,%d) vertex %d off the sphere", name, m, n, index)
+				}
+			}
+			for index := range grid.Edges {
+				correct, err := EdgeVertsInCorrectOrientation(grid, int32(index))
+				if err != nil || !correct {
+					t.Errorf("%s (%d,%d) edge %d vertices out of order: %v", name, m, n, index, err)
+				}
+				correct, err = EdgePrevNextConsistent(grid, int32(index))
+				if err != nil || !correct {
+					t.Errorf("%s (%d,%d) edge %d inconsistent: %v", name, m, n, index, err)
+				}
+			}
+			for index := range grid.Faces {
+				correct, err := FaceEdgesMatchesOrderFromEdge(grid, int32(index))
+				if err != nil || !correct {
+					t.Errorf("%s (%d,%d) face %d edges out of order: %v", name, m, n, index, err)
+				}
+			}
+			// FaceOrientation expects equilateral faces, so check outward
+			// normals as the validator does
+			if report := grid.Validate(ValidateOptions{Spherical: true}); !report.Valid() {
+				t.Errorf("%s (%d,%d) is invalid: %s", name, m, n, report.Err())
+			}
+		}
+	}
+}
+
+func TestSubdivideGeodesicClassI(t *testing.T) {
+	icosahedron, _ := BaseIcosahedron()
+	geodesic, err := icosahedron.SubdivideGeodesic(4, 0)
+	if err != nil {
+		t.Fatalf("Failed to subdivide: %s", err)
+	}
+	triangles, err := icosahedron.SubdivideTriangles(3)
+	if err != nil {
+		t.Fatalf("Failed to subdivide: %s", err)
+	}
+	// same numbering of the vertices on old vertices and edges, each at the
+	// same place up to the spacing, which is flat rather than by angle
+	if len(geodesic.Vertices) != len(triangles.Vertices) {
+		t.Fatalf("Vertex counts differ: %d %d", len(geodesic.Vertices), len(triangles.Vertices))
+	}
+	for index := 0; index < len(icosahedron.Vertices)+3*len(icosahedron.Edges); index++ {
+		if angle := vectorAngle(geodesic.Vertices[index].Coords, triangles.Vertices[index].Coords); angle > 0.05 {
+			t.Errorf("Vertex %d is %f radians from its class I position", index, angle)
+		}
+	}
+}
+
+func TestSubdivideGeodesicSkew(t *testing.T) {
+	octahedron, _ := BaseOctahedron()
+	grid, err := octahedron.SubdivideGeodesic(2, 1)
+	if err != nil {
+		t.Fatalf("Failed to subdivide: %s", err)
+	}
+	// gcd(2, 1) = 1 so no vertex lies on an old edge, the old vertices keep
+	// their four edges and all new vertices have six
+	for index, vertex := range grid.Vertices {
+		var expected int = 6
+		if index < len(octahedron.Vertices) {
+			expected = 4
+		}
+		if len(vertex.Edges) != expected {
+			t.Errorf("Vertex %d has %d edges, expected %d", index, len(vertex.Edges), expected)
+		}
+	}
+	if len(grid.Vertices) != len(octahedron.Vertices)+len(octahedron.Faces)*3 {
+		t.Errorf("Expected three interior vertices per old face, got %d vertices", len(grid.Vertices))
+	}
+}
+
+func TestSubdivideGeodesicErrors(t *testing.T) {
+	icosahedron, _ := BaseIcosahedron()
+	for _, breakdown := range [][2]int32{{0, 0}, {-1, 1}, {2, -1}} {
+		if _, err := icosahedron.SubdivideGeodesic(breakdown[0], breakdown[1]); err == nil {
+			t.Errorf("Expected an error for (%d,%d)", breakdown[0], breakdown[1])
+		}
+	}
+	// rejected from the counts, before building the lattice
+	for _, breakdown := range [][2]int32{{12000, 0}, {1<<31 - 1, 1<<31 - 1}, {40000, 1}} {
+		if _, err := icosahedron.SubdivideGeodesic(breakdown[0], breakdown[1]); err == nil {
+			t.Errorf("Expected an error for too many elements at (%d,%d)", breakdown[0], breakdown[1])
+		}
+	}
+	cube, _ := BaseCube()
+	if _, err := cube.SubdivideGeodesic(2, 1); err == nil {
+		t.Error("Expected an error subdividing quads")
+	}
+}
@@ -0,0 +1,264 @@
+package wingedGrid
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+)
+
+// GeoJSON export of faces as longitude/latitude polygons.
+//
+// The +z axis points to the north pole and longitude is measured from +x
+// toward +y. Face edges are drawn as straight lines in longitude and latitude.
+// Faces crossing the antimeridian are split into a MultiPolygon, faces
+// containing a pole are closed along the antimeridian and the pole, and a
+// vertex on a pole is drawn as a segment of the pole at the longitudes of its
+// neighbors. Exterior rings are counter-clockwise as in RFC 7946.
+
+// options for WingedGrid.WriteGeoJSON
+type GeoJSONOptions struct {
+	// returns the properties of a face, nil for none. Every feature also gets
+	// its face index as the property FaceProperty, which these can't contain.
+	Properties func(faceIndex int32) map[string]interface{}
+	// name of the face index property, "face" if empty
+	FaceProperty string
+}
+
+type geoJSONGeometry struct {
+	Type        string      `json:"type"`
+	Coordinates interface{} `json:"coordinates"`
+}
+
+type geoJSONFeature struct {
+	Type       string                 `json:"type"`
+	Geometry   geoJSONGeometry        `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+// a longitude and latitude in degrees
+type lonLat [2]float64
+
+// Writes every face as a feature of a GeoJSON FeatureCollection
+func (theGrid WingedGrid) WriteGeoJSON(w io.Writer, options GeoJSONOptions) error {
+	faceVertices, err := theGrid.faceVertexLists()
+	if err != nil {
+		return err
+	}
+	err = theGrid.checkNoSeamFaces(faceVertices)
+	if err != nil {
+		return err
+	}
+	var faceProperty string = options.FaceProperty
+	if faceProperty == "" {
+		faceProperty = "face"
+	}
+	var out *bufio.Writer = bufio.NewWriter(w)
+	var encoder *json.Encoder = json.NewEncoder(out)
+	out.WriteString(`{"type":"FeatureCollection","features":[`)
+	for faceIndex, vertices := range faceVertices {
+		if faceIndex > 0 {
+			out.WriteString(",")
+		}
+		var feature geoJSONFeature = geoJSONFeature{Type: "Feature", Properties: make(map[string]interface{})}
+		if options.Properties != nil {
+			for key, value := range options.Properties(int32(faceIndex)) {
+				if key == faceProperty {
+					return fmt.Errorf("Face %d has a property %q, which is the face index property", faceIndex, key)
+				}
+				feature.Properties[key] = value
+			}
+		}
+		feature.Properties[faceProperty] = faceIndex
+
+		var coords [][3]float64 = make([][3]float64, len(vertices))
+		for i, vertexIndex := range vertices {
+			coords[i] = theGrid.Vertices[vertexIndex].Coords
+		}
+		var polygons [][]lonLat = lonLatPolygons(coords)
+		if len(polygons) == 1 {
+			feature.Geometry = geoJSONGeometry{Type: "Polygon", Coordinates: [][]lonLat{polygons[0]}}
+		} else {
+			var multi [][][]lonLat
+			for _, ring := range polygons {
+				multi = append(multi, [][]lonLat{ring})
+			}
+			feature.Geometry = geoJSONGeometry{Type: "MultiPolygon", Coordinates: multi}
+		}
+		err = encoder.Encode(feature)
+		if err != nil {
+			return err
+		}
+	}
+	out.WriteString("]}\n")
+	return out.Flush()
+}
+
+// Returns the closed, counter-clockwise rings covering a spherical polygon
+// given by its vertices in clockwise face order
+func lonLatPolygons(coords [][3]float64) [][]lonLat {
+	var points []lonLat = poleExpandedLonLats(coords)
+
+	// unwrap longitude so it changes by less than 180 along each edge
+	var unwrapped []lonLat = make([]lonLat, len(points))
+	unwrapped[0] = points[0]
+	for i := 1; i < len(points); i++ {
+		unwrapped[i] = lonLat{unwrapped[i-1][0] + wrapDegrees(points[i][0]-points[i-1][0]), points[i][1]}
+	}
+	var winding float64 = unwrapped[len(unwrapped)-1][0] + wrapDegrees(points[0][0]-points[len(points)-1][0]) - unwrapped[0][0]
+
+	var rings [][]lonLat
+	if math.Abs(winding) > 180 {
+		// the polygon goes once around a pole, north if eastward
+		var poleLat float64 = 90
+		if winding < 0 {
+			poleLat = -90
+			for i, j := 0, len(unwrapped)-1; i < j; i, j = i+1, j-1 {
+				unwrapped[i], unwrapped[j] = unwrapped[j], unwrapped[i]
+			}
+			// restart the unwrap so longitude increases from the new first point
+			var start lonLat = unwrapped[0]
+			start[0] = wrapDegrees(start[0])
+			for i := 1; i < len(unwrapped); i++ {
+				unwrapped[i][0] = unwrapped[i][0] - unwrapped[0][0] + start[0]
+			}
+			unwrapped[0] = start
+		}
+		rings = append(rings, poleRing(unwrapped, poleLat))
+	} else {
+		var minLon, maxLon float64 = unwrapped[0][0], unwrapped[0][0]
+		for _, point := range unwrapped {
+			minLon = math.Min(minLon, point[0])
+			maxLon = math.Max(maxLon, point[0])
+		}
+		if maxLon > 180 {
+			rings = append(rings, clipLon(unwrapped, 180, true, 0), clipLon(unwrapped, 180, false, -360))
+		} else if minLon < -180 {
+			rings = append(rings, clipLon(unwrapped, -180, false, 0), clipLon(unwrapped, -180, true, 360))
+		} else {
+			rings = append(rings, unwrapped)
+		}
+	}
+
+	var closed [][]lonLat
+	for _, ring := range rings {
+		ring = dedupeLonLats(ring)
+		if len(ring) < 3 {
+			continue
+		}
+		if lonLatArea(ring) < 0 {
+			for i, j := 0, len(ring)-1; i < j; i, j = i+1, j-1 {
+				ring[i], ring[j] = ring[j], ring[i]
+			}
+		}
+		closed = append(closed, append(ring, ring[0]))
+	}
+	return closed
+}
+
+// Returns longitude and latitude for each point, replacing a point on a pole,
+// whose longitude is undefined, with points on the pole at the longitudes of
+// its neighbors
+func poleExpandedLonLats(coords [][3]float64) []lonLat {
+	var points []lonLat = make([]lonLat, len(coords))
+	var onPole []bool = make([]bool, len(coords))
+	for i, coord := range coords {
+		var radius float64 = vectorLength(coord)
+		var horizontal float64 = math.Hypot(coord[0], coord[1])
+		points[i] = lonLat{wrapDegrees(math.Atan2(coord[1], coord[0]) * 180 / math.Pi), math.Asin(coord[2]/radius) * 180 / math.Pi}
+		onPole[i] = horizontal <= radius*1e-12
+	}
+	var expanded []lonLat
+	for i, point := range points {
+		if !onPole[i] {
+			expanded = append(expanded, point)
+			continue
+		}
+		var prev, next lonLat = points[(i+len(points)-1)%len(points)], points[(i+1)%len(points)]
+		expanded = append(expanded, lonLat{prev[0], math.Copysign(90, point[1])}, lonLat{next[0], math.Copysign(90, point[1])})
+	}
+	return expanded
+}
+
+// Returns the ring from points with increasing unwrapped longitude covering
+// one full turn, cut at the antimeridian and closed through the pole
+func poleRing(unwrapped []lonLat, poleLat float64) []lonLat {
+	var count int = len(unwrapped)
+	// the first point again, a full turn later
+	var ring []lonLat = append(append([]lonLat(nil), unwrapped...), lonLat{unwrapped[0][0] + 360, unwrapped[0][1]})
+	for i := 0; i < count; i++ {
+		if ring[i][0] < 180 && ring[i+1][0] >= 180 {
+			var crossing float64 = interpolateLat(ring[i], ring[i+1], 180)
+			var result []lonLat = []lonLat{{-180, crossing}}
+			for j := i + 1; j < count; j++ {
+				result = append(result, lonLat{ring[j][0] - 360, ring[j][1]})
+			}
+			result = append(result, ring[:i+1]...)
+			return append(result, lonLat{180, crossing}, lonLat{180, poleLat}, lonLat{-180, poleLat})
+		}
+	}
+	return unwrapped
+}
+
+// Returns the part of the ring on one side of the given longitude, west when
+// keepWest, shifted by the offset
+func clipLon(ring []lonLat, lon float64, keepWest bool, offset float64) []lonLat {
+	var inside = func(point lonLat) bool {
+		if keepWest {
+			return point[0] <= lon
+		}
+		return point[0] >= lon
+	}
+	var clipped []lonLat
+	for i, current := range ring {
+		var next lonLat = ring[(i+1)%len(ring)]
+		if inside(current) {
+			clipped = append(clipped, lonLat{current[0] + offset, current[1]})
+		}
+		if inside(current) != inside(next) {
+			clipped = append(clipped, lonLat{lon + offset, interpolateLat(current, next, lon)})
+		}
+	}
+	return clipped
+}
+
+// latitude where the segment between the points reaches the longitude
+func interpolateLat(first, second lonLat, lon float64) float64 {
+	if second[0] == first[0] {
+		return first[1]
+	}
+	return first[1] + (second[1]-first[1])*(lon-first[0])/(second[0]-first[0])
+}
+
+// wraps an angle in degrees to [-180, 180)
+func wrapDegrees(angle float64) float64 {
+	angle = math.Mod(angle+180, 360)
+	if angle < 0 {
+		angle += 360
+	}
+	return angle - 180
+}
+
+func dedupeLonLats(ring []lonLat) []lonLat {
+	var result []lonLat
+	for _, point := range ring {
+		if len(result) == 0 || result[len(result)-1] != point {
+			result = append(result, point)
+		}
+	}
+	for len(result) > 1 && result[0] == result[len(result)-1] {
+		result = result[:len(result)-1]
+	}
+	return result
+}
+
+// signed area of an open ring, positive for counter-clockwise
+func lonLatArea(ring []lonLat) float64 {
+	var area float64
+	for i, current := range ring {
+		var next lonLat = ring[(i+1)%len(ring)]
+		area += current[0]*next[1] - next[0]*current[1]
+	}
+	return area / 2
+}
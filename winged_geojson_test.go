@@ -0,0 +1,201 @@
+package wingedGrid
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"testing"
+)
+
+type geoJSONTestCollection struct {
+	Type     string
+	Features []struct {
+		Geometry struct {
+			Type        string
+			Coordinates json.RawMessage
+		}
+		Properties map[string]interface{}
+	}
+}
+
+// rotates the grid so the given direction points along +z
+func rotateToNorth(theGrid WingedGrid, direction [3]float64) {
+	up, _ := normalize3VectorWithScale(direction)
+	// rotate about the axis up x z by the angle between them
+	axis := [3]float64{up[1], -up[0], 0}
+	sine := vectorLength(axis)
+	cosine := up[2]
+	if sine == 0 {
+		return
+	}
+	axis = [3]float64{axis[0] / sine, axis[1] / sine, 0}
+	for i, vertex := range theGrid.Vertices {
+		v := vertex.Coords
+		dot := axis[0]*v[0] + axis[1]*v[1] + axis[2]*v[2]
+		cross := [3]float64{axis[1]*v[2] - axis[2]*v[1], axis[2]*v[0] - axis[0]*v[2], axis[0]*v[1] - axis[1]*v[0]}
+		for j := 0; j < 3; j++ {
+			theGrid.Vertices[i].Coords[j] = v[j]*cosine + cross[j]*sine + axis[j]*dot*(1-cosine)
+		}
+	}
+}
+
+// the polygons of every feature, and the total of their areas
+func geoJSONRings(t *testing.T, theGrid WingedGrid) ([][][]lonLat, float64) {
+	var buffer bytes.Buffer
+	err := theGrid.WriteGeoJSON(&buffer, GeoJSONOptions{})
+	if err != nil {
+		t.Fatalf("Failed to write GeoJSON: %s", err)
+	}
+	var collection geoJSONTestCollection
+	if err := json.Unmarshal(buffer.Bytes(), &collection); err != nil {
+		t.Fatalf("Invalid JSON: %s", err)
+	}
+	var features [][][]lonLat
+	var total float64
+	for index, feature := range collection.Features {
+		var polygons [][][]lonLat
+		if feature.Geometry.Type == "Polygon" {
+			var polygon [][]lonLat
+			json.Unmarshal(feature.Geometry.Coordinates, &polygon)
+			polygons = [][][]lonLat{polygon}
+		} else {
+			json.Unmarshal(feature.Geometry.Coordinates, &polygons)
+		}
+		var rings [][]lonLat
+		for _, polygon := range polygons {
+			ring := polygon[0]
+			if ring[0] != ring[len(ring)-1] {
+				t.Fatalf("Feature %d ring not closed", index)
+			}
+			for _, point := range ring {
+				if point[0] < -180 || point[0] > 180 || point[1] < -90 || point[1] > 90 {
+					t.Fatalf("Feature %d has point %v out of range", index, point)
+				}
+			}
+			area := lonLatArea(ring[:len(ring)-1])
+			if area <= 0 {
+				t.Fatalf("Feature %d ring not counter-clockwise", index)
+			}
+			total += area
+			rings = append(rings, ring)
+		}
+		features = append(features, rings)
+	}
+	return features, total
+}
+
+func TestGeoJSONTilesTheMap(t *testing.T) {
+	base, _ := BaseIcosahedron()
+	grid, _ := base.SubdivideTriangles(3)
+	dual, _ := grid.CreateDual()
+	// as built, with a pentagon centered on the pole, and with a vertex on it
+	pentagonGrid, _ := base.SubdivideTriangles(3)
+	rotateToNorth(pentagonGrid, pentagonGrid.Vertices[0].Coords)
+	pentagon, _ := pentagonGrid.CreateDual()
+	vertexGrid, _ := base.SubdivideTriangles(3)
+	center, _ := vertexGrid.FaceCenter(0)
+	rotateToNorth(vertexGrid, center)
+	vertex, _ := vertexGrid.CreateDual()
+	for name, theGrid := range map[string]WingedGrid{"dual": dual, "pentagon": pentagon, "vertex": vertex} {
+		features, total := geoJSONRings(t, theGrid)
+		if len(features) != len(theGrid.Faces) {
+			t.Fatalf("%s: expected %d features, got %d", name, len(theGrid.Faces), len(features))
+		}
+		// the polygons should exactly cover the map
+		if math.Abs(total-360*180) > 1e-6 {
+			t.Errorf("%s: polygons cover %f square degrees, expected %d", name, total, 360*180)
+		}
+	}
+}
+
+func TestGeoJSONPoleAndAntimeridian(t *testing.T) {
+	var lat = math.Sqrt(3) / 2
+	var ring [][3]float64
+	for i := 0; i < 5; i++ {
+		angle := float64(i) * 2 * math.Pi / 5
+		ring = append(ring, [3]float64{0.5 * math.Cos(angle), 0.5 * math.Sin(angle), lat})
+	}
+	north := lonLatPolygons(ring)
+	if len(north) != 1 || lonLatArea(north[0][:len(north[0])-1]) <= 0 {
+		t.Fatalf("Unexpected north polygon %v", north)
+	}
+	var reachesPole bool
+	for _, point := range north[0] {
+		reachesPole = reachesPole || point[1] == 90
+	}
+	if !reachesPole {
+		t.Errorf("North polygon doesn't reach the pole: %v", north[0])
+	}
+
+	// mirrored through the equator and reversed to stay outward facing
+	var south [][3]float64
+	for i := len(ring) - 1; i >= 0; i-- {
+		south = append(south, [3]float64{ring[i][0], ring[i][1], -ring[i][2]})
+	}
+	southPolygons := lonLatPolygons(south)
+	if len(southPolygons) != 1 || math.Abs(lonLatArea(southPolygons[0][:len(southPolygons[0])-1])-lonLatArea(north[0][:len(north[0])-1])) > 1e-9 {
+		t.Errorf("Expected a south polygon matching the north one, got %v", southPolygons)
+	}
+
+	// a triangle straddling 180 degrees
+	toCoords := func(lon, lat float64) [3]float64 {
+		lon, lat = lon*math.Pi/180, lat*math.Pi/180
+		return [3]float64{math.Cos(lat) * math.Cos(lon), math.Cos(lat) * math.Sin(lon), math.Sin(lat)}
+	}
+	split := lonLatPolygons([][3]float64{toCoords(170, 0), toCoords(-170, 0), toCoords(180, 10)})
+	if len(split) != 2 {
+		t.Fatalf("Expected the triangle to be split, got %v", split)
+	}
+	for _, polygon := range split {
+		for _, point := range polygon {
+			if math.Abs(point[0]) < 169 {
+				t.Errorf("Split polygon wrapped the wrong way: %v", polygon)
+			}
+		}
+	}
+}
+
+func TestGeoJSONProperties(t *testing.T) {
+	grid, _ := BaseIcosahedron()
+	var buffer bytes.Buffer
+	err := grid.WriteGeoJSON(&buffer, GeoJSONOptions{Properties: func(faceIndex int32) map[string]interface{} {
+		return map[string]interface{}{"elevation": float64(faceIndex) * 10}
+	}})
+	if err != nil {
+		t.Fatalf("Failed to write GeoJSON: %s", err)
+	}
+	var collection geoJSONTestCollection
+	json.Unmarshal(buffer.Bytes(), &collection)
+	if collection.Type != "FeatureCollection" || len(collection.Features) != 20 {
+		t.Fatalf("Unexpected collection %s with %d features", collection.Type, len(collection.Features))
+	}
+	properties := collection.Features[7].Properties
+	if properties["elevation"] != 70.0 || properties["face"] != 7.0 {
+		t.Errorf("Unexpected properties %v", properties)
+	}
+}
+
+func TestGeoJSONFaceProperty(t *testing.T) {
+	grid, _ := BaseIcosahedron()
+	var properties = func(faceIndex int32) map[string]interface{} {
+		return map[string]interface{}{"face": "ocean"}
+	}
+	var buffer bytes.Buffer
+	if err := grid.WriteGeoJSON(&buffer, GeoJSONOptions{Properties: properties}); err == nil {
+		t.Error("Expected an error for a property named like the face index")
+	}
+
+	buffer.Reset()
+	err := grid.WriteGeoJSON(&buffer, GeoJSONOptions{Properties: properties, FaceProperty: "cell"})
+	if err != nil {
+		t.Fatalf("Failed to write GeoJSON: %s", err)
+	}
+	var collection geoJSONTestCollection
+	json.Unmarshal(buffer.Bytes(), &collection)
+	if len(collection.Features) != 20 {
+		t.Fatalf("Expected 20 features, got %d", len(collection.Features))
+	}
+	if found := collection.Features[3].Properties; found["face"] != "ocean" || found["cell"] != 3.0 {
+		t.Errorf("Unexpected properties %v", found)
+	}
+}
@@ -0,0 +1,243 @@
+package wingedGrid
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// glTF 2.0 binary (.glb) export for web viewers.
+//
+// Each face is triangulated as a fan from its first vertex, which is exact for
+// the convex faces built by this package. Vertices are repeated for every
+// face they belong to so that each triangle can carry the index of the
+// WingedFace it came from, in the custom float attribute _FACE_INDEX, which is
+// only exact for up to 2^24 faces. Normals are the smooth vertex normals, see
+// OBJOptions.Normals, or where the face normals around a vertex cancel out its
+// direction from the origin, or failing that the normal of the face.
+
+// options for WingedGrid.WriteGLB
+type GLBOptions struct {
+	// nil, or one colour per vertex, written as COLOR_0
+	VertexColors [][3]uint8
+}
+
+const (
+	glbMagic        = 0x46546C67 // "glTF"
+	glbVersion      = 2
+	glbChunkJSON    = 0x4E4F534A // "JSON"
+	glbChunkBIN     = 0x004E4942 // "BIN\0"
+	glArrayBuffer   = 34962
+	glElementBuffer = 34963
+	glUnsignedByte  = 5121
+	glUnsignedInt   = 5125
+	glFloat         = 5126
+	glTriangles     = 4
+
+	// the most faces whose indices are exact as float32
+	glbMaxFaces = 1 << 24
+)
+
+type gltfDocument struct {
+	Asset       gltfAsset        `json:"asset"`
+	Scene       int              `json:"scene"`
+	Scenes      []gltfScene      `json:"scenes"`
+	Nodes       []gltfNode       `json:"nodes"`
+	Meshes      []gltfMesh       `json:"meshes"`
+	Buffers     []gltfBuffer     `json:"buffers"`
+	BufferViews []gltfBufferView `json:"bufferViews"`
+	Accessors   []gltfAccessor   `json:"accessors"`
+}
+
+type gltfAsset struct {
+	Version   string `json:"version"`
+	Generator string `json:"generator"`
+}
+
+type gltfScene struct {
+	Nodes []int `json:"nodes"`
+}
+
+type gltfNode struct {
+	Mesh int `json:"mesh"`
+}
+
+type gltfMesh struct {
+	Primitives []gltfPrimitive `json:"primitives"`
+}
+
+type gltfPrimitive struct {
+	Attributes map[string]int `json:"attributes"`
+	Indices    int            `json:"indices"`
+	Mode       int            `json:"mode"`
+}
+
+type gltfBuffer struct {
+	ByteLength int `json:"byteLength"`
+}
+
+type gltfBufferView struct {
+	Buffer     int `json:"buffer"`
+	ByteOffset int `json:"byteOffset"`
+	ByteLength int `json:"byteLength"`
+	Target     int `json:"target"`
+}
+
+type gltfAccessor struct {
+	BufferView    int       `json:"bufferView"`
+	ComponentType int       `json:"componentType"`
+	Normalized    bool      `json:"normalized,omitempty"`
+	Count         int       `json:"count"`
+	Type          string    `json:"type"`
+	Min           []float64 `json:"min,omitempty"`
+	Max           []float64 `json:"max,omitempty"`
+}
+
+// Writes the grid as a single triangle mesh in a binary glTF file
+func (theGrid WingedGrid) WriteGLB(w io.Writer, options GLBOptions) error {
+	if options.VertexColors != nil && len(options.VertexColors) != len(theGrid.Vertices) {
+		return fmt.Errorf("Vertex colors has %d values, expected %d", len(options.VertexColors), len(theGrid.Vertices))
+	}
+	if len(theGrid.Faces) == 0 {
+		return errors.New("No faces to write")
+	}
+	if len(theGrid.Faces) > glbMaxFaces {
+		return fmt.Errorf("Grid has %d faces, _FACE_INDEX is only exact up to %d", len(theGrid.Faces), glbMaxFaces)
+	}
+	faceVertices, err := theGrid.faceVertexLists()
+	if err != nil {
+		return err
+	}
+	err = theGrid.checkNoSeamFaces(faceVertices)
+	if err != nil {
+		return err
+	}
+	var normals [][3]float64 = theGrid.vertexNormals(faceVertices)
+
+	// each face gets its own copy of its vertices
+	var vertexCount, indexCount int
+	for _, vertices := range faceVertices {
+		vertexCount += len(vertices)
+		indexCount += 3 * (len(vertices) - 2)
+	}
+	if vertexCount > math.MaxUint32 {
+		return errors.New("Grid too large for glTF")
+	}
+	var positions, vertexNormals, colors, faceIndices, indices bytes.Buffer
+	var min, max [3]float64 = [3]float64{math.Inf(1), math.Inf(1), math.Inf(1)}, [3]float64{math.Inf(-1), math.Inf(-1), math.Inf(-1)}
+	var first uint32
+	for faceIndex, vertices := range faceVertices {
+		for _, vertexIndex := range vertices {
+			var normal [3]float64 = normals[vertexIndex]
+			if vectorLength(normal) == 0 {
+				normal = theGrid.fallbackNormal(int32(faceIndex), vertexIndex)
+			}
+			var coords [3]float64 = theGrid.Vertices[vertexIndex].Coords
+			for i := 0; i < 3; i++ {
+				// bounds of the values as stored
+				var value float64 = float64(float32(coords[i]))
+				min[i] = math.Min(min[i], value)
+				max[i] = math.Max(max[i], value)
+			}
+			writeFloat32s(&positions, coords[:]...)
+			writeFloat32s(&vertexNormals, normal[:]...)
+			if options.VertexColors != nil {
+				var color [3]uint8 = options.VertexColors[vertexIndex]
+				colors.Write([]byte{color[0], color[1], color[2], 255})
+			}
+			writeFloat32s(&faceIndices, float64(faceIndex))
+		}
+		for i := 1; i+1 < len(vertices); i++ {
+			binary.Write(&indices, binary.LittleEndian, [3]uint32{first, first + uint32(i), first + uint32(i+1)})
+		}
+		first += uint32(len(vertices))
+	}
+
+	var document gltfDocument = gltfDocument{
+		Asset:  gltfAsset{Version: "2.0", Generator: "WingedGrid"},
+		Scenes: []gltfScene{{Nodes: []int{0}}},
+		Nodes:  []gltfNode{{Mesh: 0}},
+	}
+	var body bytes.Buffer
+	// appends a buffer view and its accessor, returning the accessor index
+	var addAccessor = func(data *bytes.Buffer, target int, accessor gltfAccessor) int {
+		document.BufferViews = append(document.BufferViews, gltfBufferView{
+			ByteOffset: body.Len(), ByteLength: data.Len(), Target: target,
+		})
+		body.Write(data.Bytes())
+		accessor.BufferView = len(document.BufferViews) - 1
+		document.Accessors = append(document.Accessors, accessor)
+		return len(document.Accessors) - 1
+	}
+	var primitive gltfPrimitive = gltfPrimitive{Attributes: make(map[string]int), Mode: glTriangles}
+	primitive.Attributes["POSITION"] = addAccessor(&positions, glArrayBuffer, gltfAccessor{
+		ComponentType: glFloat, Count: vertexCount, Type: "VEC3", Min: min[:], Max: max[:],
+	})
+	primitive.Attributes["NORMAL"] = addAccessor(&vertexNormals, glArrayBuffer, gltfAccessor{
+		ComponentType: glFloat, Count: vertexCount, Type: "VEC3",
+	})
+	if options.VertexColors != nil {
+		primitive.Attributes["COLOR_0"] = addAccessor(&colors, glArrayBuffer, gltfAccessor{
+			ComponentType: glUnsignedByte, Normalized: true, Count: vertexCount, Type: "VEC4",
+		})
+	}
+	primitive.Attributes["_FACE_INDEX"] = addAccessor(&faceIndices, glArrayBuffer, gltfAccessor{
+		ComponentType: glFloat, Count: vertexCount, Type: "SCALAR",
+	})
+	primitive.Indices = addAccessor(&indices, glElementBuffer, gltfAccessor{
+		ComponentType: glUnsignedInt, Count: indexCount, Type: "SCALAR",
+	})
+	document.Meshes = []gltfMesh{{Primitives: []gltfPrimitive{primitive}}}
+	document.Buffers = []gltfBuffer{{ByteLength: body.Len()}}
+
+	jsonData, err := json.Marshal(document)
+	if err != nil {
+		return err
+	}
+	// chunks are padded to four bytes, json with spaces
+	for len(jsonData)%4 != 0 {
+		jsonData = append(jsonData, ' ')
+	}
+	for body.Len()%4 != 0 {
+		body.WriteByte(0)
+	}
+	var totalLength int = 12 + 8 + len(jsonData) + 8 + body.Len()
+	if totalLength > math.MaxUint32 {
+		return errors.New("Grid too large for glTF")
+	}
+
+	var out bytes.Buffer
+	binary.Write(&out, binary.LittleEndian, [3]uint32{glbMagic, glbVersion, uint32(totalLength)})
+	binary.Write(&out, binary.LittleEndian, [2]uint32{uint32(len(jsonData)), glbChunkJSON})
+	out.Write(jsonData)
+	binary.Write(&out, binary.LittleEndian, [2]uint32{uint32(body.Len()), glbChunkBIN})
+	out.Write(body.Bytes())
+	_, err = out.WriteTo(w)
+	return err
+}
+
+func writeFloat32s(buffer *bytes.Buffer, values ...float64) {
+	var scratch [4]byte
+	for _, value := range values {
+		binary.LittleEndian.PutUint32(scratch[:], math.Float32bits(float32(value)))
+		buffer.Write(scratch[:])
+	}
+}
+
+// Returns a unit normal for a vertex whose face normals cancel out: its
+// direction from the origin, the normal of the face, or +z
+func (theGrid WingedGrid) fallbackNormal(faceIndex, vertexIndex int32) [3]float64 {
+	var direction [3]float64 = theGrid.Vertices[vertexIndex].Coords
+	if vectorLength(direction) == 0 {
+		direction, _, _ = theGrid.faceNormalAndCenter(faceIndex)
+	}
+	var length float64 = vectorLength(direction)
+	if length == 0 || math.IsNaN(length) || math.IsInf(length, 0) {
+		return [3]float64{0, 0, 1}
+	}
+	return vectorScale(direction, 1/length)
+}
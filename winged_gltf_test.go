@@ -0,0 +1,132 @@
+package wingedGrid
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"math"
+	"testing"
+)
+
+// splits a glb into its json document and binary body
+func readGLB(t *testing.T, data []byte) (gltfDocument, []byte) {
+	var document gltfDocument
+	if len(data) < 20 || binary.LittleEndian.Uint32(data) != glbMagic || binary.LittleEndian.Uint32(data[4:]) != glbVersion {
+		t.Fatal("Bad glb header")
+	}
+	if int(binary.LittleEndian.Uint32(data[8:])) != len(data) {
+		t.Fatalf("Header length %d, file length %d", binary.LittleEndian.Uint32(data[8:]), len(data))
+	}
+	jsonLength := int(binary.LittleEndian.Uint32(data[12:]))
+	if binary.LittleEndian.Uint32(data[16:]) != glbChunkJSON || jsonLength%4 != 0 {
+		t.Fatal("Bad json chunk")
+	}
+	if err := json.Unmarshal(data[20:20+jsonLength], &document); err != nil {
+		t.Fatalf("Bad json: %s", err)
+	}
+	binStart := 20 + jsonLength
+	binLength := int(binary.LittleEndian.Uint32(data[binStart:]))
+	if binary.LittleEndian.Uint32(data[binStart+4:]) != glbChunkBIN || binStart+8+binLength != len(data) {
+		t.Fatal("Bad binary chunk")
+	}
+	return document, data[binStart+8:]
+}
+
+func TestGLBFaceIndices(t *testing.T) {
+	base, _ := BaseIcosahedron()
+	grid, _ := base.SubdivideTriangles(1)
+	dual, _ := grid.CreateDual()
+	var buffer bytes.Buffer
+	if err := dual.WriteGLB(&buffer, GLBOptions{}); err != nil {
+		t.Fatalf("Failed to write glb: %s", err)
+	}
+	document, body := readGLB(t, buffer.Bytes())
+
+	primitive := document.Meshes[0].Primitives[0]
+	if _, ok := primitive.Attributes["COLOR_0"]; ok {
+		t.Error("Unexpected colours")
+	}
+	// 12 pentagons and 30 hexagons, three and four triangles each
+	indices := document.Accessors[primitive.Indices]
+	if indices.Count != 3*(12*3+30*4) {
+		t.Fatalf("Expected %d indices, got %d", 3*(12*3+30*4), indices.Count)
+	}
+	faceAccessor := document.Accessors[primitive.Attributes["_FACE_INDEX"]]
+	faceView := document.BufferViews[faceAccessor.BufferView]
+	indexView := document.BufferViews[indices.BufferView]
+	// every triangle should map back to a single face
+	var triangles = make(map[int]int)
+	for i := 0; i < indices.Count; i += 3 {
+		var faces [3]float32
+		for j := 0; j < 3; j++ {
+			vertex := binary.LittleEndian.Uint32(body[indexView.ByteOffset+4*(i+j):])
+			faces[j] = math.Float32frombits(binary.LittleEndian.Uint32(body[faceView.ByteOffset+4*int(vertex):]))
+		}
+		if faces[0] != faces[1] || faces[1] != faces[2] {
+			t.Fatalf("Triangle %d spans faces %v", i/3, faces)
+		}
+		triangles[int(faces[0])]++
+	}
+	for faceIndex, face := range dual.Faces {
+		if triangles[faceIndex] != len(face.Edges)-2 {
+			t.Errorf("Face %d has %d triangles, expected %d", faceIndex, triangles[faceIndex], len(face.Edges)-2)
+		}
+	}
+}
+
+func TestGLBColors(t *testing.T) {
+	grid, _ := BaseIcosahedron()
+	colors := make([][3]uint8, len(grid.Vertices))
+	for i := range colors {
+		colors[i] = [3]uint8{uint8(i), 20, 30}
+	}
+	var buffer bytes.Buffer
+	if err := grid.WriteGLB(&buffer, GLBOptions{VertexColors: colors}); err != nil {
+		t.Fatalf("Failed to write glb: %s", err)
+	}
+	document, body := readGLB(t, buffer.Bytes())
+	primitive := document.Meshes[0].Primitives[0]
+	colorAccessor := document.Accessors[primitive.Attributes["COLOR_0"]]
+	if colorAccessor.Count != 60 || colorAccessor.Type != "VEC4" || !colorAccessor.Normalized {
+		t.Fatalf("Unexpected colour accessor %+v", colorAccessor)
+	}
+	// written in face order
+	firstVertex := mustFaceVertexLists(t, grid)[0][0]
+	offset := document.BufferViews[colorAccessor.BufferView].ByteOffset
+	if !bytes.Equal(body[offset:offset+4], []byte{uint8(firstVertex), 20, 30, 255}) {
+		t.Errorf("Unexpected first colour %v", body[offset:offset+4])
+	}
+	position := document.Accessors[primitive.Attributes["POSITION"]]
+	if position.Max[2] != float64(float32(goldenRatio)) {
+		t.Errorf("Unexpected position bounds %v %v", position.Min, position.Max)
+	}
+
+	if err := grid.WriteGLB(&buffer, GLBOptions{VertexColors: colors[1:]}); err == nil {
+		t.Error("Expected an error for too few colours")
+	}
+}
+
+func TestGLBDegenerateNormals(t *testing.T) {
+	// two triangles back to back, whose normals cancel at every vertex, and
+	// one vertex at the origin
+	grid, err := NewGridFromPolygons([][3]float64{{1, 0, 0}, {0, 1, 0}, {0, 0, 0}}, [][]int32{{0, 1, 2}, {0, 2, 1}})
+	if err != nil {
+		t.Fatalf("Failed to build grid: %s", err)
+	}
+	var buffer bytes.Buffer
+	if err := grid.WriteGLB(&buffer, GLBOptions{}); err != nil {
+		t.Fatalf("Failed to write glb: %s", err)
+	}
+	document, body := readGLB(t, buffer.Bytes())
+	normals := document.Accessors[document.Meshes[0].Primitives[0].Attributes["NORMAL"]]
+	view := document.BufferViews[normals.BufferView]
+	for i := 0; i < normals.Count; i++ {
+		var normal [3]float64
+		for j := range normal {
+			normal[j] = float64(math.Float32frombits(binary.LittleEndian.Uint32(body[view.ByteOffset+12*i+4*j:])))
+		}
+		if math.Abs(vectorLength(normal)-1) > 1e-6 {
+			t.Errorf("Normal %d is %v", i, normal)
+		}
+	}
+}
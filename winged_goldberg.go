@@ -0,0 +1,26 @@
+package wingedGrid
+
+// Creates the Goldberg polyhedron GP(m, n), the dual of the icosahedron
+// subdivided by SubdivideGeodesic(m, n). It has
+// 12 pentagons and 10*(T-1) hexagons, where T = m*m + m*n + n*n. GP(m, 0) is
+// class I, as produced by SubdivideTriangles(m-1) followed by CreateDual,
+// GP(m, m) is class II and other values are the chiral class III, with
+// GP(n, m) the mirror image of GP(m, n).
+//
+// Vertices lie on the sphere through the vertices of BaseIcosahedron.
+func GoldbergPolyhedron(m, n int32) (WingedGrid, error) {
+	icosahedron, err := BaseIcosahedron()
+	if err != nil {
+		return WingedGrid{}, err
+	}
+	geodesic, err := icosahedron.SubdivideGeodesic(m, n)
+	if err != nil {
+		return WingedGrid{}, err
+	}
+	goldberg, err := geodesic.CreateDual()
+	if err != nil {
+		return WingedGrid{}, err
+	}
+	goldberg.NormalizeVerticesToDistanceFromOrigin(vectorLength(icosahedron.Vertices[0].Coords))
+	return goldberg, nil
+}
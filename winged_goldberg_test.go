@@ -0,0 +1,91 @@
+package wingedGrid
+
+import (
+	"math"
+	"testing"
+)
+
+func TestGoldbergPolyhedron(t *testing.T) {
+	icosahedron, _ := BaseIcosahedron()
+	var radius float64 = vectorLength(icosahedron.Vertices[0].Coords)
+	for _, breakdown := range [][2]int32{{1, 0}, {2, 0}, {1, 1}, {2, 1}, {1, 2}, {3, 1}, {3, 2}, {4, 4}} {
+		var m, n int32 = breakdown[0], breakdown[1]
+		var T int = int(m*m + m*n + n*n)
+		goldberg, err := GoldbergPolyhedron(m, n)
+		if err != nil {
+			t.Fatalf("GP(%d,%d): %s", m, n, err)
+		}
+		if len(goldberg.Faces) != 10*T+2 || len(goldberg.Edges) != 30*T || len(goldberg.Vertices) != 20*T {
+			t.Errorf("GP(%d,%d) has %d faces, %d edges and %d vertices", m, n, len(goldberg.Faces), len(goldberg.Edges), len(goldberg.Vertices))
+		}
+		if report := goldberg.Validate(ValidateOptions{Spherical: true}); !report.Valid() {
+			t.Errorf("GP(%d,%d) is invalid: %s", m, n, report.Err())
+		}
+		var sizes map[int]int = make(map[int]int)
+		for _, face := range goldberg.Faces {
+			sizes[len(face.Edges)]++
+		}
+		if sizes[5] != 12 || sizes[6] != 10*(T-1) || len(sizes) > 2 {
+			t.Errorf("GP(%d,%d) face sizes %v", m, n, sizes)
+		}
+		for index, vertex := range goldberg.Vertices {
+			if math.Abs(vectorLength(vertex.Coords)-radius) > 1e-9 {
+				t.Errorf("GP(%d,%d) vertex %d off the sphere", m, n, index)
+				break
+			}
+		}
+	}
+}
+
+func TestGoldbergPolyhedronPentagonSpacing(t *testing.T) {
+	// neighboring pentagons of GP(m, n) are m+n face steps apart, so in
+	// GP(2, 1) the shortest path of faces between two pentagons has length 3
+	goldberg, err := GoldbergPolyhedron(2, 1)
+	if err != nil {
+		t.Fatalf("GP(2,1): %s", err)
+	}
+	var distances []int = make([]int, len(goldberg.Faces))
+	for i := range distances {
+		distances[i] = -1
+	}
+	var start int32 = -1
+	for index, face := range goldberg.Faces {
+		if len(face.Edges) == 5 {
+			start = int32(index)
+			break
+		}
+	}
+	distances[start] = 0
+	var queue []int32 = []int32{start}
+	for len(queue) > 0 {
+		var current int32 = queue[0]
+		queue = queue[1:]
+		neighbors, err := goldberg.NeighborsForFace(current)
+		if err != nil {
+			t.Fatalf("Neighbors of %d: %s", current, err)
+		}
+		for _, neighbor := range neighbors {
+			if distances[neighbor] < 0 {
+				distances[neighbor] = distances[current] + 1
+				queue = append(queue, neighbor)
+			}
+		}
+	}
+	var nearest int = -1
+	for index, face := range goldberg.Faces {
+		if len(face.Edges) == 5 && int32(index) != start && (nearest < 0 || distances[index] < nearest) {
+			nearest = distances[index]
+		}
+	}
+	if nearest != 3 {
+		t.Errorf("Nearest pentagon is %d faces away, expected 3", nearest)
+	}
+}
+
+func TestGoldbergPolyhedronInvalid(t *testing.T) {
+	for _, breakdown := range [][2]int32{{0, 0}, {-1, 2}, {12000, 0}} {
+		if _, err := GoldbergPolyhedron(breakdown[0], breakdown[1]); err == nil {
+			t.Errorf("Expected an error for GP(%d,%d)", breakdown[0], breakdown[1])
+		}
+	}
+}
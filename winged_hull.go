@@ -0,0 +1,494 @@
+package wingedGrid
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Convex hulls of point sets, by quickhull (Barber, Dobkin and Huhdanpaa
+// 1996). Faces are built as triangles linked to their neighbors, and points
+// are only compared to the faces they may be outside of. Coplanar triangles
+// are then merged into polygons unless a triangulation is asked for.
+
+// options for ConvexHull
+type ConvexHullOptions struct {
+	// split each face into a fan of triangles from its first corner, rather
+	// than leaving coplanar triangles merged
+	Triangulate bool
+}
+
+// Builds the convex hull of the points as a closed grid with faces oriented
+// outward. Its vertices are the corners of the hull, in the order they appear
+// in points, and points inside the hull or on its faces and edges are left
+// out. Points are compared with a tolerance from the rounding error of their
+// largest coordinates, so faces within that of being coplanar are merged.
+//
+// Returns an error if there are fewer than four points, or they all lie on a
+// plane.
+func ConvexHull(points [][3]float64, options ConvexHullOptions) (WingedGrid, error) {
+	hull, err := newQuickhull(points)
+	if err != nil {
+		return WingedGrid{}, err
+	}
+	// merged first even for triangles, which drops points on faces and edges
+	faces, err := hull.mergedFaces()
+	if err != nil {
+		return WingedGrid{}, err
+	}
+	if options.Triangulate {
+		var triangles [][]int32
+		for _, polygon := range faces {
+			for k := 1; k+1 < len(polygon); k++ {
+				triangles = append(triangles, []int32{polygon[0], polygon[k], polygon[k+1]})
+			}
+		}
+		faces = triangles
+	}
+
+	// keep only the corners, in point order
+	var newIndex []int32 = make([]int32, len(points))
+	for _, face := range faces {
+		for _, vertexIndex := range face {
+			newIndex[vertexIndex] = 1
+		}
+	}
+	var coords [][3]float64
+	for index, used := range newIndex {
+		if used != 0 {
+			newIndex[index] = int32(len(coords))
+			coords = append(coords, points[index])
+		}
+	}
+	for _, face := range faces {
+		for i, vertexIndex := range face {
+			face[i] = newIndex[vertexIndex]
+		}
+	}
+	return NewGridFromPolygons(coords, faces)
+}
+
+// a triangle of the hull, with vertices clockwise in the sense of WingedFace
+type hullFace struct {
+	vertices [3]int32
+	// the neighbor across the edge from vertices[k] to vertices[k+1]
+	neighbors [3]int32
+	// outward unit normal, and its dot product with points of the face
+	normal [3]float64
+	offset float64
+	// points outside the face, not yet on the hull
+	outside []int32
+	// visible from a point being added, and so no longer on the hull
+	removed bool
+}
+
+type quickhull struct {
+	points [][3]float64
+	// distances from a face within this are on it
+	epsilon float64
+	faces   []hullFace
+	// the faces visible from the point being added
+	visible []int32
+	// edges of visible faces whose neighbor isn't visible, as the face index
+	// and the edge in it, in clockwise order around the visible region
+	horizon [][2]int32
+}
+
+// Builds the triangulated convex hull of the points. Points inside the hull
+// aren't used, but points on its faces and edges may be.
+func newQuickhull(points [][3]float64) (*quickhull, error) {
+	if len(points) < 4 {
+		return nil, errors.New("Convex hull needs at least four points")
+	}
+	if len(points) > maxGridElements {
+		return nil, errors.New("Too many elements for int32 indices")
+	}
+	var hull *quickhull = &quickhull{points: points}
+	var largest [3]float64
+	for index, point := range points {
+		for axis, value := range point {
+			if math.IsNaN(value) || math.IsInf(value, 0) {
+				return nil, fmt.Errorf("Point %d is not finite", index)
+			}
+			largest[axis] = math.Max(largest[axis], math.Abs(value))
+		}
+	}
+	// as suggested for quickhull, from the rounding error of the distance
+	hull.epsilon = 3 * 2.220446049250313e-16 * (largest[0] + largest[1] + largest[2])
+
+	err := hull.createSimplex()
+	if err != nil {
+		return nil, err
+	}
+	// a single pass, as only new faces are given outside points
+	for faceIndex := 0; faceIndex < len(hull.faces); faceIndex++ {
+		if hull.faces[faceIndex].removed || len(hull.faces[faceIndex].outside) == 0 {
+			continue
+		}
+		err = hull.addPoint(int32(faceIndex))
+		if err != nil {
+			return nil, err
+		}
+	}
+	return hull, nil
+}
+
+// Returns the faces on the hull
+func (hull *quickhull) triangles() []hullFace {
+	var faces []hullFace
+	for _, face := range hull.faces {
+		if !face.removed {
+			faces = append(faces, face)
+		}
+	}
+	return faces
+}
+
+func (hull *quickhull) distance(faceIndex, pointIndex int32) float64 {
+	var face *hullFace = &hull.faces[faceIndex]
+	return vectorDot(face.normal, hull.points[pointIndex]) - face.offset
+}
+
+// Appends a face, setting its plane from its vertices
+func (hull *quickhull) addFace(vertices [3]int32) int32 {
+	var a, b, c [3]float64 = hull.points[vertices[0]], hull.points[vertices[1]], hull.points[vertices[2]]
+	var normal [3]float64 = vectorCross(vectorSub(b, a), vectorSub(c, a))
+	var length float64 = vectorLength(normal)
+	if length > 0 {
+		normal = [3]float64{normal[0] / length, normal[1] / length, normal[2] / length}
+	}
+	var center [3]float64 = [3]float64{(a[0] + b[0] + c[0]) / 3, (a[1] + b[1] + c[1]) / 3, (a[2] + b[2] + c[2]) / 3}
+	hull.faces = append(hull.faces, hullFace{
+		vertices:  vertices,
+		neighbors: [3]int32{-1, -1, -1},
+		normal:    normal,
+		offset:    vectorDot(normal, center),
+	})
+	return int32(len(hull.faces) - 1)
+}
+
+// Puts the point in the outside list of the face it is furthest outside of,
+// returning false if it is outside none of them
+func (hull *quickhull) assignPoint(pointIndex int32, faces []int32) bool {
+	var best int32 = -1
+	var bestDistance float64 = hull.epsilon
+	for _, faceIndex := range faces {
+		var distance float64 = hull.distance(faceIndex, pointIndex)
+		if distance > bestDistance {
+			best = faceIndex
+			bestDistance = distance
+		}
+	}
+	if best < 0 {
+		return false
+	}
+	hull.faces[best].outside = append(hull.faces[best].outside, pointIndex)
+	return true
+}
+
+// Starts the hull with a tetrahedron of points far apart
+func (hull *quickhull) createSimplex() error {
+	var points [][3]float64 = hull.points
+	// the pair furthest apart along an axis
+	var first, second int32
+	var spread float64 = -1
+	for axis := 0; axis < 3; axis++ {
+		var low, high int32
+		for index, point := range points {
+			if point[axis] < points[low][axis] {
+				low = int32(index)
+			}
+			if point[axis] > points[high][axis] {
+				high = int32(index)
+			}
+		}
+		if points[high][axis]-points[low][axis] > spread {
+			spread = points[high][axis] - points[low][axis]
+			first, second = low, high
+		}
+	}
+	if spread <= hull.epsilon {
+		return errors.New("Convex hull points are all the same")
+	}
+
+	// furthest from the line between them
+	var direction [3]float64 = vectorSub(points[second], points[first])
+	var third int32 = -1
+	var furthest float64 = 0
+	for index, point := range points {
+		var distance float64 = vectorLength(vectorCross(direction, vectorSub(point, points[first]))) / vectorLength(direction)
+		if distance > furthest {
+			third = int32(index)
+			furthest = distance
+		}
+	}
+	if furthest <= hull.epsilon {
+		return errors.New("Convex hull points are all on a line")
+	}
+
+	// furthest from the plane through all three
+	var normal [3]float64 = vectorCross(direction, vectorSub(points[third], points[first]))
+	var fourth int32 = -1
+	furthest = 0
+	var above bool
+	for index, point := range points {
+		var distance float64 = vectorDot(normal, vectorSub(point, points[first])) / vectorLength(normal)
+		if math.Abs(distance) > furthest {
+			fourth = int32(index)
+			furthest = math.Abs(distance)
+			above = distance > 0
+		}
+	}
+	if furthest <= hull.epsilon {
+		return errors.New("Convex hull points are all on a plane")
+	}
+
+	// the base faces away from the fourth point
+	var a, b, c, d int32 = first, second, third, fourth
+	if above {
+		b, c = c, b
+	}
+	var faces [4]int32 = [4]int32{
+		hull.addFace([3]int32{a, b, c}),
+		hull.addFace([3]int32{b, a, d}),
+		hull.addFace([3]int32{c, b, d}),
+		hull.addFace([3]int32{a, c, d}),
+	}
+	hull.linkFaces(faces[:])
+
+	for index := range points {
+		if int32(index) != a && int32(index) != b && int32(index) != c && int32(index) != d {
+			hull.assignPoint(int32(index), faces[:])
+		}
+	}
+	return nil
+}
+
+// Sets the neighbors of faces that only border each other
+func (hull *quickhull) linkFaces(faces []int32) {
+	var edges map[vertexPair]int32 = make(map[vertexPair]int32)
+	for _, faceIndex := range faces {
+		var face *hullFace = &hull.faces[faceIndex]
+		for k := 0; k < 3; k++ {
+			var pair vertexPair = newVertexPair(face.vertices[k], face.vertices[(k+1)%3])
+			other, found := edges[pair]
+			if !found {
+				edges[pair] = faceIndex
+				continue
+			}
+			face.neighbors[k] = other
+			var otherFace *hullFace = &hull.faces[other]
+			for j := 0; j < 3; j++ {
+				if otherFace.vertices[j] == face.vertices[(k+1)%3] {
+					otherFace.neighbors[j] = faceIndex
+				}
+			}
+		}
+	}
+}
+
+// Adds the point furthest outside the face to the hull, replacing the faces
+// it can see with a cone of faces from it to their horizon
+func (hull *quickhull) addPoint(faceIndex int32) error {
+	var outside []int32 = hull.faces[faceIndex].outside
+	var pointIndex int32 = outside[0]
+	var furthest float64 = hull.distance(faceIndex, pointIndex)
+	for _, candidate := range outside[1:] {
+		if distance := hull.distance(faceIndex, candidate); distance > furthest {
+			pointIndex = candidate
+			furthest = distance
+		}
+	}
+
+	hull.visible = hull.visible[:0]
+	hull.horizon = hull.horizon[:0]
+	hull.findHorizon(pointIndex, faceIndex, -1)
+
+	var cone []int32 = make([]int32, len(hull.horizon))
+	for i, horizonEdge := range hull.horizon {
+		var face hullFace = hull.faces[horizonEdge[0]]
+		var k int32 = horizonEdge[1]
+		var a, b int32 = face.vertices[k], face.vertices[(k+1)%3]
+		var previous [2]int32 = hull.horizon[(i+len(hull.horizon)-1)%len(hull.horizon)]
+		if hull.faces[previous[0]].vertices[(previous[1]+1)%3] != a {
+			return fmt.Errorf("Convex hull horizon of point %d is not a single loop", pointIndex)
+		}
+		cone[i] = hull.addFace([3]int32{a, b, pointIndex})
+		// the face across the horizon now borders the cone
+		var across int32 = face.neighbors[k]
+		hull.faces[cone[i]].neighbors[0] = across
+		for j := 0; j < 3; j++ {
+			if hull.faces[across].neighbors[j] == horizonEdge[0] && hull.faces[across].vertices[j] == b {
+				hull.faces[across].neighbors[j] = cone[i]
+			}
+		}
+	}
+	for i, coneFace := range cone {
+		hull.faces[coneFace].neighbors[1] = cone[(i+1)%len(cone)]
+		hull.faces[coneFace].neighbors[2] = cone[(i+len(cone)-1)%len(cone)]
+	}
+
+	for _, visibleIndex := range hull.visible {
+		for _, candidate := range hull.faces[visibleIndex].outside {
+			if candidate != pointIndex {
+				hull.assignPoint(candidate, cone)
+			}
+		}
+		hull.faces[visibleIndex].outside = nil
+	}
+	return nil
+}
+
+// Marks the face as visible from the point and walks on to its visible
+// neighbors, collecting the horizon in order. The walk into a face continues
+// from the edge after the one it crossed, or from the first edge to start.
+func (hull *quickhull) findHorizon(pointIndex, faceIndex, crossed int32) {
+	hull.faces[faceIndex].removed = true
+	hull.visible = append(hull.visible, faceIndex)
+	var start, count int32 = 0, 3
+	if crossed >= 0 {
+		start, count = crossed+1, 2
+	}
+	for i := int32(0); i < count; i++ {
+		var k int32 = (start + i) % 3
+		var neighbor int32 = hull.faces[faceIndex].neighbors[k]
+		if hull.faces[neighbor].removed {
+			continue
+		}
+		if hull.distance(neighbor, pointIndex) > hull.epsilon {
+			// the neighbor's edge back runs from this edge's end
+			var back int32
+			for j := int32(0); j < 3; j++ {
+				if hull.faces[neighbor].vertices[j] == hull.faces[faceIndex].vertices[(k+1)%3] {
+					back = j
+				}
+			}
+			hull.findHorizon(pointIndex, neighbor, back)
+		} else {
+			hull.horizon = append(hull.horizon, [2]int32{faceIndex, k})
+		}
+	}
+}
+
+// Returns the hull faces with coplanar neighboring triangles merged into
+// polygons, without corners where the polygon runs straight on
+func (hull *quickhull) mergedFaces() ([][]int32, error) {
+	// union find over coplanar neighbors
+	var group []int32 = make([]int32, len(hull.faces))
+	var find func(index int32) int32
+	find = func(index int32) int32 {
+		for group[index] != index {
+			group[index] = group[group[index]]
+			index = group[index]
+		}
+		return index
+	}
+	for index := range group {
+		group[index] = int32(index)
+	}
+	for index, face := range hull.faces {
+		if face.removed {
+			continue
+		}
+		for k, neighbor := range face.neighbors {
+			if neighbor > int32(index) && hull.coplanar(int32(index), neighbor, k) {
+				group[find(neighbor)] = find(int32(index))
+			}
+		}
+	}
+
+	// each group's outer edges, from the first vertex to the second
+	var next map[int32]map[int32]int32 = make(map[int32]map[int32]int32)
+	var order []int32
+	// the first outer edge found starts the polygon
+	var starts map[int32]int32 = make(map[int32]int32)
+	for index, face := range hull.faces {
+		if face.removed {
+			continue
+		}
+		var root int32 = find(int32(index))
+		if next[root] == nil {
+			next[root] = make(map[int32]int32)
+			order = append(order, root)
+		}
+		for k, neighbor := range face.neighbors {
+			if find(neighbor) == root {
+				continue
+			}
+			var first int32 = face.vertices[k]
+			if _, found := next[root][first]; found {
+				return nil, fmt.Errorf("Convex hull face at point %d is not a simple polygon", first)
+			}
+			if len(next[root]) == 0 {
+				starts[root] = first
+			}
+			next[root][first] = face.vertices[(k+1)%3]
+		}
+	}
+
+	var faces [][]int32
+	for _, root := range order {
+		var polygon []int32
+		var start int32 = starts[root]
+		for vertex := start; len(polygon) == 0 || vertex != start; vertex = next[root][vertex] {
+			polygon = append(polygon, vertex)
+			if len(polygon) > len(next[root]) {
+				break
+			}
+		}
+		if len(polygon) != len(next[root]) {
+			return nil, fmt.Errorf("Convex hull face at point %d is not a simple polygon", start)
+		}
+		faces = append(faces, hull.withoutStraightCorners(polygon))
+	}
+	return faces, nil
+}
+
+// Returns true if the face across edge k of the face lies in its plane
+func (hull *quickhull) coplanar(faceIndex, neighbor int32, k int) bool {
+	var face, other *hullFace = &hull.faces[faceIndex], &hull.faces[neighbor]
+	if vectorDot(face.normal, other.normal) <= 0 {
+		return false
+	}
+	// the corners opposite the shared edge
+	var opposite, otherOpposite int32 = face.vertices[(k+2)%3], -1
+	for _, vertexIndex := range other.vertices {
+		if vertexIndex != face.vertices[k] && vertexIndex != face.vertices[(k+1)%3] {
+			otherOpposite = vertexIndex
+		}
+	}
+	return math.Abs(hull.distance(faceIndex, otherOpposite)) <= hull.epsilon &&
+		math.Abs(hull.distance(neighbor, opposite)) <= hull.epsilon
+}
+
+// Removes vertices on the straight line between their neighbors
+func (hull *quickhull) withoutStraightCorners(polygon []int32) []int32 {
+	for removed := true; removed && len(polygon) > 3; {
+		removed = false
+		for i, vertexIndex := range polygon {
+			var prev, next [3]float64 = hull.points[polygon[(i+len(polygon)-1)%len(polygon)]], hull.points[polygon[(i+1)%len(polygon)]]
+			var direction [3]float64 = vectorSub(next, prev)
+			var offLine float64 = vectorLength(vectorCross(direction, vectorSub(hull.points[vertexIndex], prev))) / vectorLength(direction)
+			if offLine <= hull.epsilon {
+				polygon = append(polygon[:i:i], polygon[i+1:]...)
+				removed = true
+				break
+			}
+		}
+	}
+	return polygon
+}
+
+func vectorSub(first, second [3]float64) [3]float64 {
+	return [3]float64{first[0] - second[0], first[1] - second[1], first[2] - second[2]}
+}
+func vectorDot(first, second [3]float64) float64 {
+	return first[0]*second[0] + first[1]*second[1] + first[2]*second[2]
+}
+func vectorCross(first, second [3]float64) [3]float64 {
+	return [3]float64{
+		first[1]*second[2] - first[2]*second[1],
+		first[2]*second[0] - first[0]*second[2],
+		first[0]*second[1] - first[1]*second[0],
+	}
+}
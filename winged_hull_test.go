@@ -0,0 +1,154 @@
+package wingedGrid
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+// checks the grid is a closed convex hull of the points
+func checkConvexHull(t *testing.T, grid WingedGrid, points [][3]float64) {
+	if report := grid.Validate(ValidateOptions{Spherical: true}); !report.Valid() {
+		t.Fatalf("Hull invalid: %s", report.Err())
+	}
+	if euler := len(grid.Vertices) - len(grid.Edges) + len(grid.Faces); euler != 2 {
+		t.Errorf("Expected Euler characteristic 2, got %d", euler)
+	}
+	for faceIndex := range grid.Faces {
+		normal, _, _ := grid.faceNormalAndCenter(int32(faceIndex))
+		var length float64 = vectorLength(normal)
+		var corner [3]float64 = grid.Vertices[mustFaceVertexLists(t, grid)[faceIndex][0]].Coords
+		for index, point := range points {
+			if vectorDot(normal, vectorSub(point, corner))/length > 1e-9 {
+				t.Fatalf("Point %d is outside face %d", index, faceIndex)
+			}
+		}
+	}
+}
+
+func TestConvexHullCube(t *testing.T) {
+	var random *rand.Rand = rand.New(rand.NewSource(4))
+	var points [][3]float64
+	// points inside, on faces and on edges first
+	for i := 0; i < 200; i++ {
+		var point [3]float64 = [3]float64{2*random.Float64() - 1, 2*random.Float64() - 1, 2*random.Float64() - 1}
+		points = append(points, point)
+		point[i%3] = 1
+		points = append(points, point)
+		point[(i+1)%3] = -1
+		points = append(points, point)
+	}
+	for _, z := range []float64{-1, 1} {
+		for _, y := range []float64{-1, 1} {
+			for _, x := range []float64{-1, 1} {
+				points = append(points, [3]float64{x, y, z})
+			}
+		}
+	}
+
+	cube, err := ConvexHull(points, ConvexHullOptions{})
+	if err != nil {
+		t.Fatalf("Failed to build hull: %s", err)
+	}
+	if len(cube.Vertices) != 8 || len(cube.Edges) != 12 || len(cube.Faces) != 6 {
+		t.Fatalf("Expected a cube, got %d vertices, %d edges and %d faces", len(cube.Vertices), len(cube.Edges), len(cube.Faces))
+	}
+	// corners in point order
+	if cube.Vertices[0].Coords != [3]float64{-1, -1, -1} || cube.Vertices[7].Coords != [3]float64{1, 1, 1} {
+		t.Errorf("Unexpected vertex order")
+	}
+	checkConvexHull(t, cube, points)
+
+	triangulated, err := ConvexHull(points, ConvexHullOptions{Triangulate: true})
+	if err != nil {
+		t.Fatalf("Failed to build triangulated hull: %s", err)
+	}
+	for index, face := range triangulated.Faces {
+		if len(face.Edges) != 3 {
+			t.Errorf("Face %d has %d edges", index, len(face.Edges))
+		}
+	}
+	checkConvexHull(t, triangulated, points)
+}
+
+func TestConvexHullLatticeCube(t *testing.T) {
+	var points [][3]float64
+	for z := 0; z < 5; z++ {
+		for y := 0; y < 5; y++ {
+			for x := 0; x < 5; x++ {
+				points = append(points, [3]float64{float64(x - 2), float64(y - 2), float64(z - 2)})
+			}
+		}
+	}
+	for _, triangulate := range []bool{false, true} {
+		cube, err := ConvexHull(points, ConvexHullOptions{Triangulate: triangulate})
+		if err != nil {
+			t.Fatalf("Failed to build hull: %s", err)
+		}
+		var faces int = 6
+		if triangulate {
+			faces = 12
+		}
+		if len(cube.Vertices) != 8 || len(cube.Faces) != faces {
+			t.Errorf("Expected 8 corners and %d faces, got %d vertices and %d faces", faces, len(cube.Vertices), len(cube.Faces))
+		}
+		checkConvexHull(t, cube, points)
+	}
+}
+
+func TestConvexHullRandomCloud(t *testing.T) {
+	var random *rand.Rand = rand.New(rand.NewSource(5))
+	var points [][3]float64
+	for i := 0; i < 5000; i++ {
+		points = append(points, [3]float64{random.NormFloat64(), 2 * random.NormFloat64(), 0.5 * random.NormFloat64()})
+	}
+	hull, err := ConvexHull(points, ConvexHullOptions{})
+	if err != nil {
+		t.Fatalf("Failed to build hull: %s", err)
+	}
+	checkConvexHull(t, hull, points)
+}
+
+func TestConvexHullMatchesSphericalDelaunay(t *testing.T) {
+	var points [][3]float64 = randomSpherePoints(1000, 6)
+	delaunay, err := SphericalDelaunay(points)
+	if err != nil {
+		t.Fatalf("Failed to triangulate: %s", err)
+	}
+	hull, err := ConvexHull(points, ConvexHullOptions{Triangulate: true})
+	if err != nil {
+		t.Fatalf("Failed to build hull: %s", err)
+	}
+	var triangleSet = func(grid WingedGrid) map[[3]int32]bool {
+		var set map[[3]int32]bool = make(map[[3]int32]bool)
+		for _, vertices := range mustFaceVertexLists(t, grid) {
+			var sorted []int32 = append([]int32(nil), vertices...)
+			sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+			set[[3]int32{sorted[0], sorted[1], sorted[2]}] = true
+		}
+		return set
+	}
+	var expected map[[3]int32]bool = triangleSet(delaunay)
+	var got map[[3]int32]bool = triangleSet(hull)
+	if len(got) != len(expected) {
+		t.Fatalf("Expected %d triangles, got %d", len(expected), len(got))
+	}
+	for triangle := range expected {
+		if !got[triangle] {
+			t.Errorf("Hull is missing triangle %v", triangle)
+		}
+	}
+}
+
+func TestConvexHullInvalid(t *testing.T) {
+	if _, err := ConvexHull([][3]float64{{0, 0, 0}, {1, 0, 0}, {0, 1, 0}}, ConvexHullOptions{}); err == nil {
+		t.Error("Expected an error for three points")
+	}
+	var flat [][3]float64
+	for i := 0; i < 10; i++ {
+		flat = append(flat, [3]float64{float64(i % 3), float64(i / 3), 0})
+	}
+	if _, err := ConvexHull(flat, ConvexHullOptions{}); err == nil {
+		t.Error("Expected an error for points on a plane")
+	}
+}
@@ -0,0 +1,171 @@
+package wingedGrid
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Loop subdivision (Loop 1987) of triangle grids, a smoothing scheme for
+// surfaces that aren't spheres. Sharp features follow the crease rules of
+// Hoppe et al. 1994, with boundary edges always treated as creases.
+
+// options for WingedGrid.SubdivideLoop
+type LoopOptions struct {
+	// edges kept sharp, their pieces staying sharp at later levels
+	Creases []int32
+	// vertices that don't move
+	Corners []int32
+}
+
+// Splits each triangle into four, levels times, and smooths the result with
+// the Loop rules. Each old vertex moves toward its neighbors, and a new vertex
+// on each edge is placed from the edge and the two triangles beside it.
+//
+// Along creases and the boundary only the vertices of the crease count:
+// a vertex with two crease edges moves along them, one with three or more
+// stays put as a corner, and a crease edge gets its midpoint.
+//
+// At each level the old vertices keep their index, followed by one vertex per
+// old edge. Old face f, with vertices v0, v1, v2 and new vertices m0, m1, m2
+// on its edges from them, becomes faces 4f to 4f+3: the corners
+// (v0, m0, m2), (v1, m1, m0), (v2, m2, m1) and the middle (m0, m1, m2).
+func (oldGrid WingedGrid) SubdivideLoop(levels int32, options LoopOptions) (WingedGrid, error) {
+	if levels < 1 {
+		return WingedGrid{}, errors.New("Loop subdivision needs at least one level")
+	}
+	var creases map[vertexPair]bool = make(map[vertexPair]bool)
+	for _, edgeIndex := range options.Creases {
+		if !indexInRange(edgeIndex, len(oldGrid.Edges)) {
+			return WingedGrid{}, fmt.Errorf("Crease edge %d out of range", edgeIndex)
+		}
+		var edge WingedEdge = oldGrid.Edges[edgeIndex]
+		creases[newVertexPair(edge.FirstVertexA, edge.FirstVertexB)] = true
+	}
+	var corners []bool = make([]bool, len(oldGrid.Vertices))
+	for _, vertexIndex := range options.Corners {
+		if !indexInRange(vertexIndex, len(oldGrid.Vertices)) {
+			return WingedGrid{}, fmt.Errorf("Corner vertex %d out of range", vertexIndex)
+		}
+		corners[vertexIndex] = true
+	}
+
+	var grid WingedGrid = oldGrid
+	var err error
+	for level := int32(0); level < levels; level++ {
+		grid, creases, err = grid.loopLevel(creases, corners)
+		if err != nil {
+			return WingedGrid{}, err
+		}
+		// new vertices are never corners
+		corners = append(corners, make([]bool, len(grid.Vertices)-len(corners))...)
+	}
+	return grid, nil
+}
+
+// Returns the grid after one level of Loop subdivision, and the pieces of the
+// creases
+func (oldGrid WingedGrid) loopLevel(creases map[vertexPair]bool, corners []bool) (WingedGrid, map[vertexPair]bool, error) {
+	faceVertices, err := oldGrid.faceVertexLists()
+	if err != nil {
+		return WingedGrid{}, nil, err
+	}
+	for faceIndex, vertices := range faceVertices {
+		if len(vertices) != 3 {
+			return WingedGrid{}, nil, fmt.Errorf("Face %d has %d edges, Loop subdivision needs triangles", faceIndex, len(vertices))
+		}
+	}
+	var vertexCount int = len(oldGrid.Vertices) + len(oldGrid.Edges)
+	if vertexCount > maxGridElements || len(oldGrid.Faces)*4 > maxGridElements {
+		return WingedGrid{}, nil, errors.New("Too many elements for int32 indices")
+	}
+
+	var sharp []bool = make([]bool, len(oldGrid.Edges))
+	for edgeIndex, edge := range oldGrid.Edges {
+		sharp[edgeIndex] = edge.IsBoundary() || creases[newVertexPair(edge.FirstVertexA, edge.FirstVertexB)]
+	}
+
+	var coords [][3]float64 = make([][3]float64, vertexCount)
+	for index, vertex := range oldGrid.Vertices {
+		coords[index] = oldGrid.loopVertexPosition(int32(index), vertex, sharp, corners[index])
+	}
+
+	// new edge vertices, with the corners opposite each edge added from the faces
+	var edgeOffset int = len(oldGrid.Vertices)
+	for edgeIndex, edge := range oldGrid.Edges {
+		var first, second [3]float64 = oldGrid.Vertices[edge.FirstVertexA].Coords, oldGrid.Vertices[edge.FirstVertexB].Coords
+		var weight float64 = 3.0 / 8
+		if sharp[edgeIndex] {
+			weight = 1.0 / 2
+		}
+		coords[edgeOffset+edgeIndex] = vectorAdd(vectorScale(first, weight), vectorScale(second, weight))
+	}
+	for faceIndex, vertices := range faceVertices {
+		for k, edgeIndex := range oldGrid.Faces[faceIndex].Edges {
+			if !sharp[edgeIndex] {
+				var opposite [3]float64 = oldGrid.Vertices[vertices[(k+2)%3]].Coords
+				coords[edgeOffset+int(edgeIndex)] = vectorAdd(coords[edgeOffset+int(edgeIndex)], vectorScale(opposite, 1.0/8))
+			}
+		}
+	}
+
+	var faces [][]int32 = make([][]int32, 0, 4*len(oldGrid.Faces))
+	var newCreases map[vertexPair]bool = make(map[vertexPair]bool)
+	for faceIndex, vertices := range faceVertices {
+		var middle [3]int32
+		for k, edgeIndex := range oldGrid.Faces[faceIndex].Edges {
+			middle[k] = int32(edgeOffset) + edgeIndex
+		}
+		faces = append(faces,
+			[]int32{vertices[0], middle[0], middle[2]},
+			[]int32{vertices[1], middle[1], middle[0]},
+			[]int32{vertices[2], middle[2], middle[1]},
+			[]int32{middle[0], middle[1], middle[2]})
+	}
+	for edgeIndex, edge := range oldGrid.Edges {
+		if creases[newVertexPair(edge.FirstVertexA, edge.FirstVertexB)] {
+			var middle int32 = int32(edgeOffset + edgeIndex)
+			newCreases[newVertexPair(edge.FirstVertexA, middle)] = true
+			newCreases[newVertexPair(middle, edge.FirstVertexB)] = true
+		}
+	}
+
+	newGrid, err := NewGridFromPolygons(coords, faces)
+	return newGrid, newCreases, err
+}
+
+// Returns the new position of an old vertex
+func (oldGrid WingedGrid) loopVertexPosition(vertexIndex int32, vertex WingedVertex, sharp []bool, corner bool) [3]float64 {
+	var sum, sharpSum [3]float64
+	var sharpCount int
+	for _, edgeIndex := range vertex.Edges {
+		var edge WingedEdge = oldGrid.Edges[edgeIndex]
+		var other int32 = edge.FirstVertexA
+		if other == vertexIndex {
+			other = edge.FirstVertexB
+		}
+		sum = vectorAdd(sum, oldGrid.Vertices[other].Coords)
+		if sharp[edgeIndex] {
+			sharpSum = vectorAdd(sharpSum, oldGrid.Vertices[other].Coords)
+			sharpCount++
+		}
+	}
+	if corner || sharpCount > 2 {
+		return vertex.Coords
+	}
+	if sharpCount == 2 {
+		return vectorAdd(vectorScale(vertex.Coords, 3.0/4), vectorScale(sharpSum, 1.0/8))
+	}
+	// smooth, also with a single crease ending here
+	var n float64 = float64(len(vertex.Edges))
+	var inner float64 = 3.0/8 + math.Cos(2*math.Pi/n)/4
+	var beta float64 = (5.0/8 - inner*inner) / n
+	return vectorAdd(vectorScale(vertex.Coords, 1-n*beta), vectorScale(sum, beta))
+}
+
+func vectorAdd(first, second [3]float64) [3]float64 {
+	return [3]float64{first[0] + second[0], first[1] + second[1], first[2] + second[2]}
+}
+func vectorScale(vector [3]float64, scale float64) [3]float64 {
+	return [3]float64{vector[0] * scale, vector[1] * scale, vector[2] * scale}
+}
@@ -0,0 +1,164 @@
+package wingedGrid
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSubdivideLoopCounts(t *testing.T) {
+	base, _ := BaseIcosahedron()
+	grid, err := base.SubdivideLoop(2, LoopOptions{})
+	if err != nil {
+		t.Fatalf("Failed to subdivide: %s", err)
+	}
+	if len(grid.Vertices) != 162 || len(grid.Edges) != 480 || len(grid.Faces) != 320 {
+		t.Errorf("Unexpected counts %d %d %d", len(grid.Vertices), len(grid.Edges), len(grid.Faces))
+	}
+	if report := grid.Validate(ValidateOptions{Spherical: true}); !report.Valid() {
+		t.Errorf("Subdivided grid invalid: %s", report.Err())
+	}
+	// smoothing pulls everything inside the original corners
+	var radius float64 = vectorLength(base.Vertices[0].Coords)
+	for index, vertex := range grid.Vertices {
+		if vectorLength(vertex.Coords) >= radius {
+			t.Errorf("Vertex %d wasn't pulled in", index)
+		}
+	}
+}
+
+func TestSubdivideLoopLayout(t *testing.T) {
+	base, _ := BaseTetrahedron()
+	grid, err := base.SubdivideLoop(1, LoopOptions{})
+	if err != nil {
+		t.Fatalf("Failed to subdivide: %s", err)
+	}
+	baseVertices := mustFaceVertexLists(t, base)
+	newVertices := mustFaceVertexLists(t, grid)
+	for faceIndex, vertices := range baseVertices {
+		for k := 0; k < 3; k++ {
+			if newVertices[4*faceIndex+k][0] != vertices[k] {
+				t.Errorf("Face %d doesn't start at corner %d of face %d", 4*faceIndex+k, vertices[k], faceIndex)
+			}
+		}
+	}
+	// each edge vertex is 3/8 of each end and 1/8 of each opposite corner
+	for edgeIndex, edge := range base.Edges {
+		var expected [3]float64
+		for _, vertex := range base.Vertices {
+			var weight float64 = 1.0 / 8
+			for _, end := range [2]int32{edge.FirstVertexA, edge.FirstVertexB} {
+				if base.Vertices[end].Coords == vertex.Coords {
+					weight = 3.0 / 8
+				}
+			}
+			expected = vectorAdd(expected, vectorScale(vertex.Coords, weight))
+		}
+		if distanceBetween3Points(expected, grid.Vertices[4+edgeIndex].Coords) > 1e-12 {
+			t.Errorf("Edge vertex %d at %v, expected %v", 4+edgeIndex, grid.Vertices[4+edgeIndex].Coords, expected)
+		}
+	}
+}
+
+func TestSubdivideLoopBoundary(t *testing.T) {
+	base, err := PlanarTriangleMap(6, 4, NoWrap)
+	if err != nil {
+		t.Fatalf("Failed to create map: %s", err)
+	}
+	// pin the ends of the bottom row
+	var corners []int32
+	for index, vertex := range base.Vertices {
+		if vertex.Coords[1] == 0 && (vertex.Coords[0] == 0 || vertex.Coords[0] == 6) {
+			corners = append(corners, int32(index))
+		}
+	}
+	grid, err := base.SubdivideLoop(2, LoopOptions{Corners: corners})
+	if err != nil {
+		t.Fatalf("Failed to subdivide: %s", err)
+	}
+	if report := grid.Validate(ValidateOptions{}); !report.Valid() {
+		t.Fatalf("Subdivided grid invalid: %s", report.Err())
+	}
+	for _, vertexIndex := range corners {
+		if grid.Vertices[vertexIndex].Coords != base.Vertices[vertexIndex].Coords {
+			t.Errorf("Corner %d moved", vertexIndex)
+		}
+	}
+	for index, vertex := range grid.Vertices {
+		if vertex.Coords[2] != 0 {
+			t.Errorf("Vertex %d left the plane", index)
+		}
+	}
+	// the bottom row stays on its line, only seeing boundary neighbors, while
+	// the vertices above it are pulled down but not onto it
+	var bottom int
+	for _, vertex := range grid.Vertices {
+		if math.Abs(vertex.Coords[1]) < 1e-12 {
+			bottom++
+		}
+	}
+	if bottom != 6*4+1 {
+		t.Errorf("Expected %d vertices along the bottom, got %d", 6*4+1, bottom)
+	}
+}
+
+func TestSubdivideLoopCreases(t *testing.T) {
+	var points [][3]float64
+	for _, z := range []float64{-1, 1} {
+		for _, y := range []float64{-1, 1} {
+			for _, x := range []float64{-1, 1} {
+				points = append(points, [3]float64{x, y, z})
+			}
+		}
+	}
+	cube, err := ConvexHull(points, ConvexHullOptions{Triangulate: true})
+	if err != nil {
+		t.Fatalf("Failed to build cube: %s", err)
+	}
+	// the edges of the cube, not the diagonals across its faces
+	var creases []int32
+	for index, edge := range cube.Edges {
+		if distanceBetween3Points(cube.Vertices[edge.FirstVertexA].Coords, cube.Vertices[edge.FirstVertexB].Coords) < 2.5 {
+			creases = append(creases, int32(index))
+		}
+	}
+	if len(creases) != 12 {
+		t.Fatalf("Expected 12 cube edges, got %d", len(creases))
+	}
+	grid, err := cube.SubdivideLoop(3, LoopOptions{Creases: creases})
+	if err != nil {
+		t.Fatalf("Failed to subdivide: %s", err)
+	}
+	if report := grid.Validate(ValidateOptions{Spherical: true}); !report.Valid() {
+		t.Fatalf("Subdivided grid invalid: %s", report.Err())
+	}
+	for index := 0; index < 8; index++ {
+		if grid.Vertices[index].Coords != cube.Vertices[index].Coords {
+			t.Errorf("Corner %d moved to %v", index, grid.Vertices[index].Coords)
+		}
+	}
+	// vertices on the cube edges stay on them, with two coordinates at ±1
+	var onEdges int
+	for _, vertex := range grid.Vertices {
+		var atLimit int
+		for _, value := range vertex.Coords {
+			if math.Abs(math.Abs(value)-1) < 1e-12 {
+				atLimit++
+			}
+		}
+		if atLimit >= 2 {
+			onEdges++
+		}
+	}
+	// 8 corners and 7 more along each of the 12 edges
+	if onEdges != 8+12*7 {
+		t.Errorf("Expected %d vertices on the cube edges, got %d", 8+12*7, onEdges)
+	}
+
+	if _, err := cube.SubdivideLoop(1, LoopOptions{Creases: []int32{100}}); err == nil {
+		t.Error("Expected an error for a crease out of range")
+	}
+	quads, _ := BaseCube()
+	if _, err := quads.SubdivideLoop(1, LoopOptions{}); err == nil {
+		t.Error("Expected an error for quad faces")
+	}
+}
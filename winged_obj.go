@@ -0,0 +1,199 @@
+package wingedGrid
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Wavefront OBJ export and import.
+//
+// OBJ faces list their vertices counter-clockwise when viewed from outside,
+// which is the same order as the clockwise edges of a WingedFace, so faces are
+// written and read without reordering.
+
+// options for WingedGrid.WriteOBJWithOptions
+type OBJOptions struct {
+	// write a normal for each vertex, the area weighted mean of the normals
+	// of its faces
+	Normals bool
+}
+
+// Writes the grid as an OBJ mesh with one polygon per face
+func (theGrid WingedGrid) WriteOBJ(w io.Writer) error {
+	return theGrid.WriteOBJWithOptions(w, OBJOptions{})
+}
+
+// Writes the grid as an OBJ mesh with one polygon per face
+func (theGrid WingedGrid) WriteOBJWithOptions(w io.Writer, options OBJOptions) error {
+	faceVertices, err := theGrid.faceVertexLists()
+	if err != nil {
+		return err
+	}
+	err = theGrid.checkNoSeamFaces(faceVertices)
+	if err != nil {
+		return err
+	}
+	var out *bufio.Writer = bufio.NewWriter(w)
+	fmt.Fprintf(out, "# WingedGrid: %d vertices, %d faces\n", len(theGrid.Vertices), len(theGrid.Faces))
+	for _, vertex := range theGrid.Vertices {
+		fmt.Fprintf(out, "v %s %s %s\n", formatOBJFloat(vertex.Coords[0]), formatOBJFloat(vertex.Coords[1]), formatOBJFloat(vertex.Coords[2]))
+	}
+	if options.Normals {
+		for _, normal := range theGrid.vertexNormals(faceVertices) {
+			fmt.Fprintf(out, "vn %s %s %s\n", formatOBJFloat(normal[0]), formatOBJFloat(normal[1]), formatOBJFloat(normal[2]))
+		}
+	}
+	for _, vertices := range faceVertices {
+		out.WriteString("f")
+		for _, vertexIndex := range vertices {
+			// OBJ indices start at one
+			if options.Normals {
+				fmt.Fprintf(out, " %d//%d", vertexIndex+1, vertexIndex+1)
+			} else {
+				fmt.Fprintf(out, " %d", vertexIndex+1)
+			}
+		}
+		out.WriteString("\n")
+	}
+	return out.Flush()
+}
+
+func formatOBJFloat(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+// Returns the vertices of each face in clockwise order
+func (theGrid WingedGrid) faceVertexLists() ([][]int32, error) {
+	var faceVertices [][]int32 = make([][]int32, len(theGrid.Faces))
+	for faceIndex, face := range theGrid.Faces {
+		faceVertices[faceIndex] = make([]int32, len(face.Edges))
+		for i, edgeIndex := range face.Edges {
+			vertexIndex, err := theGrid.Edges[edgeIndex].FirstVertexForFace(int32(faceIndex))
+			if err != nil {
+				return nil, fmt.Errorf("Face %d: %s", faceIndex, err)
+			}
+			faceVertices[faceIndex][i] = vertexIndex
+		}
+	}
+	return faceVertices, nil
+}
+
+// Returns a unit normal for each vertex, the area weighted mean of the normals
+// of the faces around it
+func (theGrid WingedGrid) vertexNormals(faceVertices [][]int32) [][3]float64 {
+	var normals [][3]float64 = make([][3]float64, len(theGrid.Vertices))
+	for faceIndex, vertices := range faceVertices {
+		faceNormal, _, _ := theGrid.faceNormalAndCenter(int32(faceIndex))
+		for _, vertexIndex := range vertices {
+			normals[vertexIndex][0] += faceNormal[0]
+			normals[vertexIndex][1] += faceNormal[1]
+			normals[vertexIndex][2] += faceNormal[2]
+		}
+	}
+	for index, normal := range normals {
+		var length float64 = vectorLength(normal)
+		if length > 0 {
+			normals[index] = [3]float64{normal[0] / length, normal[1] / length, normal[2] / length}
+		}
+	}
+	return normals
+}
+
+// Reads an OBJ mesh and builds a fully linked grid from its vertices and
+// faces. Texture coordinates, normals, groups and materials are ignored. The
+// faces must form an orientable surface, which may have a boundary. Vertices
+// no face uses are dropped, with the rest keeping their order.
+func ReadOBJ(r io.Reader) (WingedGrid, error) {
+	var coords [][3]float64
+	var faces [][]int32
+	var scanner *bufio.Scanner = bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	var lineNumber int
+	for scanner.Scan() {
+		lineNumber++
+		var fields []string = strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		switch fields[0] {
+		case "v":
+			if len(fields) < 4 {
+				return WingedGrid{}, fmt.Errorf("OBJ line %d: vertex needs three coordinates", lineNumber)
+			}
+			var vertex [3]float64
+			for i := 0; i < 3; i++ {
+				value, err := strconv.ParseFloat(fields[i+1], 64)
+				if err != nil || math.IsNaN(value) || math.IsInf(value, 0) {
+					return WingedGrid{}, fmt.Errorf("OBJ line %d: invalid coordinate %q", lineNumber, fields[i+1])
+				}
+				vertex[i] = value
+			}
+			coords = append(coords, vertex)
+		case "f":
+			var face []int32 = make([]int32, 0, len(fields)-1)
+			for _, field := range fields[1:] {
+				vertexIndex, err := parseOBJIndex(field, len(coords))
+				if err != nil {
+					return WingedGrid{}, fmt.Errorf("OBJ line %d: %s", lineNumber, err)
+				}
+				face = append(face, vertexIndex)
+			}
+			faces = append(faces, face)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return WingedGrid{}, err
+	}
+
+	// exporters often leave unused vertices, which NewGridFromPolygons rejects
+	var newIndex []int32 = make([]int32, len(coords))
+	for index := range newIndex {
+		newIndex[index] = -1
+	}
+	for _, face := range faces {
+		for _, vertexIndex := range face {
+			newIndex[vertexIndex] = 0
+		}
+	}
+	var used [][3]float64 = make([][3]float64, 0, len(coords))
+	for index := range newIndex {
+		if newIndex[index] == 0 {
+			newIndex[index] = int32(len(used))
+			used = append(used, coords[index])
+		}
+	}
+	for _, face := range faces {
+		for i, vertexIndex := range face {
+			face[i] = newIndex[vertexIndex]
+		}
+	}
+	return NewGridFromPolygons(used, faces)
+}
+
+// Returns the zero based vertex index from a face element such as "3",
+// "3/1", "3//2" or "-1", given the number of vertices read so far.
+func parseOBJIndex(field string, vertexCount int) (int32, error) {
+	var vertexField string = field
+	if slash := strings.IndexByte(field, '/'); slash >= 0 {
+		vertexField = field[:slash]
+	}
+	index, err := strconv.ParseInt(vertexField, 10, 32)
+	if err != nil {
+		return -1, fmt.Errorf("invalid face vertex %q", field)
+	}
+	if index < 0 {
+		// relative to the end of the vertices so far
+		index = int64(vertexCount) + index
+	} else {
+		index = index - 1
+	}
+	if index < 0 || index >= int64(vertexCount) {
+		return -1, errors.New("face vertex " + field + " out of range")
+	}
+	return int32(index), nil
+}
@@ -0,0 +1,161 @@
+package wingedGrid
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const octahedronOBJ = `# octahedron
+v 1 0 0
+v -1 0 0
+v 0 1 0
+v 0 -1 0
+v 0 0 1
+v 0 0 -1
+vn 0 0 1
+f 1//1 3//1 5//1
+f 3 2 5
+f 2 4 5
+f 4/1 1/1 5/1
+f 3 1 6
+f 2 3 6
+f 4 2 6
+f -6 -3 -1
+`
+
+func TestOBJRoundTrip(t *testing.T) {
+	base, _ := BaseIcosahedron()
+	grid, _ := base.SubdivideTriangles(2)
+	dual, _ := grid.CreateDual()
+	for _, theGrid := range []WingedGrid{grid, dual} {
+		var buffer bytes.Buffer
+		err := theGrid.WriteOBJ(&buffer)
+		if err != nil {
+			t.Fatalf("Failed to write OBJ: %s", err)
+		}
+		read, err := ReadOBJ(&buffer)
+		if err != nil {
+			t.Fatalf("Failed to read OBJ: %s", err)
+		}
+		if len(read.Faces) != len(theGrid.Faces) || len(read.Edges) != len(theGrid.Edges) || len(read.Vertices) != len(theGrid.Vertices) {
+			t.Fatalf("Counts differ after round trip")
+		}
+		for index, vertex := range theGrid.Vertices {
+			if vertex.Coords != read.Vertices[index].Coords {
+				t.Fatalf("Vertex %d moved from %v to %v", index, vertex.Coords, read.Vertices[index].Coords)
+			}
+		}
+		report := read.Validate(ValidateOptions{Spherical: true})
+		if !report.Valid() {
+			t.Errorf("Read grid invalid: %s", report.Err())
+		}
+	}
+}
+
+func TestOBJNormals(t *testing.T) {
+	grid, _ := BaseIcosahedron()
+	var buffer bytes.Buffer
+	err := grid.WriteOBJWithOptions(&buffer, OBJOptions{Normals: true})
+	if err != nil {
+		t.Fatalf("Failed to write OBJ: %s", err)
+	}
+	var normalCount int
+	for _, line := range strings.Split(buffer.String(), "\n") {
+		if strings.HasPrefix(line, "vn ") {
+			normalCount++
+		}
+		if strings.HasPrefix(line, "f ") && !strings.Contains(line, "//") {
+			t.Fatalf("Face without normal indices: %s", line)
+		}
+	}
+	if normalCount != len(grid.Vertices) {
+		t.Errorf("Expected %d normals, got %d", len(grid.Vertices), normalCount)
+	}
+	// vertices of a regular solid have normals along their position
+	normals := grid.vertexNormals(mustFaceVertexLists(t, grid))
+	for index, vertex := range grid.Vertices {
+		position, _ := normalize3VectorWithScale(vertex.Coords)
+		if distanceBetween3Points(position, normals[index]) > 1e-9 {
+			t.Errorf("Vertex %d normal %v not along %v", index, normals[index], position)
+		}
+	}
+}
+
+func mustFaceVertexLists(t *testing.T, theGrid WingedGrid) [][]int32 {
+	faceVertices, err := theGrid.faceVertexLists()
+	if err != nil {
+		t.Fatalf("Failed to list face vertices: %s", err)
+	}
+	return faceVertices
+}
+
+func TestReadOBJBaseForSubdivision(t *testing.T) {
+	octahedron, err := ReadOBJ(strings.NewReader(octahedronOBJ))
+	if err != nil {
+		t.Fatalf("Failed to read OBJ: %s", err)
+	}
+	if len(octahedron.Faces) != 8 || len(octahedron.Edges) != 12 || len(octahedron.Vertices) != 6 {
+		t.Fatalf("Unexpected counts %d/%d/%d", len(octahedron.Faces), len(octahedron.Edges), len(octahedron.Vertices))
+	}
+	subdivided, err := octahedron.SubdivideTriangles(3)
+	if err != nil {
+		t.Fatalf("Failed to subdivide: %s", err)
+	}
+	report := subdivided.Validate(ValidateOptions{Spherical: true})
+	if !report.Valid() {
+		t.Errorf("Subdivided grid invalid: %s", report.Err())
+	}
+}
+
+func TestReadOBJUnusedVertices(t *testing.T) {
+	// a tetrahedron with unused vertices before, between and after its own
+	const obj = `v 9 9 9
+v 1 1 1
+v 1 -1 -1
+v 8 8 8
+v -1 1 -1
+v -1 -1 1
+v 7 7 7
+f 2 3 5
+f 2 5 6
+f 2 6 3
+f 3 6 5
+`
+	grid, err := ReadOBJ(strings.NewReader(obj))
+	if err != nil {
+		t.Fatalf("Failed to read OBJ: %s", err)
+	}
+	var expected [][3]float64 = [][3]float64{{1, 1, 1}, {1, -1, -1}, {-1, 1, -1}, {-1, -1, 1}}
+	if len(grid.Vertices) != len(expected) {
+		t.Fatalf("Expected %d vertices, got %d", len(expected), len(grid.Vertices))
+	}
+	for index, coords := range expected {
+		if grid.Vertices[index].Coords != coords {
+			t.Errorf("Vertex %d at %v, expected %v", index, grid.Vertices[index].Coords, coords)
+		}
+	}
+	if !reflect.DeepEqual(mustFaceVertexLists(t, grid)[3], []int32{1, 3, 2}) {
+		t.Errorf("Unexpected last face %v", mustFaceVertexLists(t, grid)[3])
+	}
+	if report := grid.Validate(ValidateOptions{Spherical: true}); !report.Valid() {
+		t.Errorf("Grid invalid: %s", report.Err())
+	}
+}
+
+func TestReadOBJErrors(t *testing.T) {
+	cases := map[string]string{
+		"out of range":   "v 0 0 0\nv 1 0 0\nv 0 1 0\nf 1 2 4\n",
+		"zero index":     "v 0 0 0\nv 1 0 0\nv 0 1 0\nf 0 1 2\n",
+		"bad coordinate": "v 0 zero 0\n",
+		"pinched":        "v 0 0 0\nv 1 0 0\nv 0 1 0\nv -1 0 0\nv 0 -1 0\nf 1 2 3\nf 1 4 5\n",
+		// second face flipped
+		"orientation": strings.Replace(octahedronOBJ, "f 3 2 5", "f 2 3 5", 1),
+	}
+	for name, obj := range cases {
+		if _, err := ReadOBJ(strings.NewReader(obj)); err == nil {
+			t.Errorf("Expected an error for %s", name)
+		}
+	}
+}
@@ -0,0 +1,304 @@
+package wingedGrid
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Flat triangle and hexagon maps in the z=0 plane, seen from +z, so faces are
+// counter-clockwise in x and y. Edges are one unit long.
+//
+// Rectangular maps can wrap in x, y or both, joining opposite sides into a
+// cylinder or torus. Vertex coords of a wrapped map stay within one period of
+// the plane, set as WingedGrid.Period, so a face across the seam has vertices
+// at both ends of the map. FaceCoords, FaceCenter and Offset take the seams
+// into account, while the file exporters return an error for such a face.
+
+type PlanarWrap int
+
+const (
+	NoWrap PlanarWrap = 0
+	// join the left and right sides
+	WrapX PlanarWrap = 1
+	// join the top and bottom sides
+	WrapY  PlanarWrap = 2
+	WrapXY PlanarWrap = WrapX | WrapY
+)
+
+// the vertices of a planar map on an integer lattice, scaled to coords
+type planarLattice struct {
+	// lattice steps per unit of x and y
+	scale [2]float64
+	// the key period in x and y, 0 for no wrap
+	period [2]int
+	index  map[[2]int]int32
+	coords [][3]float64
+}
+
+func newPlanarLattice(scale [2]float64, period [2]int) *planarLattice {
+	return &planarLattice{scale: scale, period: period, index: make(map[[2]int]int32)}
+}
+
+// Returns the index of the vertex at the lattice key, adding it if needed
+func (lattice *planarLattice) vertex(key [2]int) int32 {
+	for axis, period := range lattice.period {
+		if period > 0 {
+			key[axis] = ((key[axis] % period) + period) % period
+		}
+	}
+	index, ok := lattice.index[key]
+	if !ok {
+		index = int32(len(lattice.coords))
+		lattice.index[key] = index
+		lattice.coords = append(lattice.coords, [3]float64{float64(key[0]) / lattice.scale[0], float64(key[1]) / lattice.scale[1], 0})
+	}
+	return index
+}
+
+// Returns the indices of the vertices at the keys
+func (lattice *planarLattice) face(keys ...[2]int) []int32 {
+	var face []int32 = make([]int32, len(keys))
+	for i, key := range keys {
+		face[i] = lattice.vertex(key)
+	}
+	return face
+}
+
+// Builds the grid of the faces, with the period of the wrap
+func (lattice *planarLattice) grid(faces [][]int32) (WingedGrid, error) {
+	newGrid, err := NewGridFromPolygons(lattice.coords, faces)
+	if err != nil {
+		return WingedGrid{}, err
+	}
+	for axis, period := range lattice.period {
+		newGrid.Period[axis] = float64(period) / lattice.scale[axis]
+	}
+	return newGrid, nil
+}
+
+// Returns an error if the map size can't be built with the wrap. minColumns
+// and minRows are the smallest sizes that wrap without joining two vertices
+// by more than one edge.
+func checkPlanarSize(columns, rows int32, wrap PlanarWrap, minColumns, minRows int32) error {
+	if wrap&^WrapXY != 0 {
+		return fmt.Errorf("Unknown planar wrap %d", wrap)
+	}
+	if columns < 1 || rows < 1 {
+		return errors.New("Planar map needs at least one column and one row")
+	}
+	if wrap&WrapX != 0 && columns < minColumns {
+		return fmt.Errorf("Wrapping in x needs at least %d columns", minColumns)
+	}
+	// odd rows are offset, so the offsets only line up for an even count
+	if wrap&WrapY != 0 && (rows < minRows || rows%2 != 0) {
+		return fmt.Errorf("Wrapping in y needs an even number of rows, at least %d", minRows)
+	}
+	if int64(columns)*int64(rows)*6 > maxGridElements {
+		return errors.New("Too many elements for int32 indices")
+	}
+	return nil
+}
+
+// Sets up a map of equilateral triangles, rows strips high and columns wide,
+// with two triangles per column in each strip. The vertices of odd rows are
+// offset half a unit in x, so an unwrapped map has ragged left and right sides.
+// Face 2*(row*columns+column) points up on even rows and down on odd rows, and
+// the face after it the other way.
+func PlanarTriangleMap(columns, rows int32, wrap PlanarWrap) (WingedGrid, error) {
+	err := checkPlanarSize(columns, rows, wrap, 3, 4)
+	if err != nil {
+		return WingedGrid{}, err
+	}
+	var period [2]int
+	if wrap&WrapX != 0 {
+		period[0] = 2 * int(columns)
+	}
+	if wrap&WrapY != 0 {
+		period[1] = int(rows)
+	}
+	// half units in x, rows in y
+	var lattice *planarLattice = newPlanarLattice([2]float64{2, 2 / math.Sqrt(3)}, period)
+	// vertex at column i of row j
+	var key = func(i, j int) [2]int {
+		return [2]int{2*i + j%2, j}
+	}
+	var faces [][]int32 = make([][]int32, 0, 2*columns*rows)
+	for j := 0; j < int(rows); j++ {
+		for i := 0; i < int(columns); i++ {
+			if j%2 == 0 {
+				faces = append(faces,
+					lattice.face(key(i, j), key(i+1, j), key(i, j+1)),
+					lattice.face(key(i+1, j), key(i+1, j+1), key(i, j+1)))
+			} else {
+				faces = append(faces,
+					lattice.face(key(i, j), key(i+1, j+1), key(i, j+1)),
+					lattice.face(key(i, j), key(i+1, j), key(i+1, j+1)))
+			}
+		}
+	}
+	return lattice.grid(faces)
+}
+
+// Sets up a map of pointy topped hexagons, columns wide and rows high, with
+// odd rows offset half a hexagon to the right. Face row*columns+column is the
+// hexagon centered at x = √3(column + (row%2)/2), y = 1.5 row.
+func PlanarHexMap(columns, rows int32, wrap PlanarWrap) (WingedGrid, error) {
+	err := checkPlanarSize(columns, rows, wrap, 2, 2)
+	if err != nil {
+		return WingedGrid{}, err
+	}
+	var period [2]int
+	if wrap&WrapX != 0 {
+		period[0] = 2 * int(columns)
+	}
+	if wrap&WrapY != 0 {
+		period[1] = 3 * int(rows)
+	}
+	var lattice *planarLattice = newPlanarLattice(hexLatticeScale, period)
+	var faces [][]int32 = make([][]int32, 0, columns*rows)
+	for j := 0; j < int(rows); j++ {
+		for i := 0; i < int(columns); i++ {
+			faces = append(faces, lattice.hexagon([2]int{2*i + j%2, 3 * j}))
+		}
+	}
+	return lattice.grid(faces)
+}
+
+// Sets up a hexagon of equilateral triangles centered on the origin, with
+// radius triangles along each side, 6*radius² faces in all.
+func PlanarTriangleHexagon(radius int32) (WingedGrid, error) {
+	if radius < 1 {
+		return WingedGrid{}, errors.New("Triangle hexagon needs a radius of at least one")
+	}
+	if int64(radius)*int64(radius)*18 > maxGridElements {
+		return WingedGrid{}, errors.New("Too many elements for int32 indices")
+	}
+	var lattice *planarLattice = newPlanarLattice([2]float64{2, 2 / math.Sqrt(3)}, [2]int{})
+	var r int = int(radius)
+	// axial coordinates, q along x and s along the row above
+	var inside = func(q, s int) bool {
+		return q >= -r && q <= r && s >= -r && s <= r && q+s >= -r && q+s <= r
+	}
+	var key = func(q, s int) [2]int {
+		return [2]int{2*q + s, s}
+	}
+	var faces [][]int32
+	for s := -r; s < r; s++ {
+		for q := -r; q <= r; q++ {
+			if inside(q, s) && inside(q+1, s) && inside(q, s+1) {
+				faces = append(faces, lattice.face(key(q, s), key(q+1, s), key(q, s+1)))
+			}
+			if inside(q+1, s) && inside(q+1, s+1) && inside(q, s+1) {
+				faces = append(faces, lattice.face(key(q+1, s), key(q+1, s+1), key(q, s+1)))
+			}
+		}
+	}
+	return lattice.grid(faces)
+}
+
+// Sets up a hexagon of pointy topped hexagons centered on the origin, with
+// radius rings around the center one, 3*radius*(radius+1)+1 faces in all.
+func PlanarHexHexagon(radius int32) (WingedGrid, error) {
+	if radius < 0 {
+		return WingedGrid{}, errors.New("Hex hexagon needs a non-negative radius")
+	}
+	if (int64(radius)+1)*(int64(radius)+1)*18 > maxGridElements {
+		return WingedGrid{}, errors.New("Too many elements for int32 indices")
+	}
+	var lattice *planarLattice = newPlanarLattice(hexLatticeScale, [2]int{})
+	var r int = int(radius)
+	var faces [][]int32
+	for s := -r; s <= r; s++ {
+		for q := -r; q <= r; q++ {
+			if q+s >= -r && q+s <= r {
+				faces = append(faces, lattice.hexagon([2]int{2*q + s, 3 * s}))
+			}
+		}
+	}
+	return lattice.grid(faces)
+}
+
+// hexagon corners are on a lattice of half √3 in x and half a unit in y
+var hexLatticeScale = [2]float64{2 / math.Sqrt(3), 2}
+
+// counter-clockwise from the upper right, as lattice offsets from the center
+var hexCorners = [6][2]int{{1, 1}, {0, 2}, {-1, 1}, {-1, -1}, {0, -2}, {1, -1}}
+
+// Returns the vertex indices of the hexagon with the center key
+func (lattice *planarLattice) hexagon(center [2]int) []int32 {
+	var face []int32 = make([]int32, len(hexCorners))
+	for i, corner := range hexCorners {
+		face[i] = lattice.vertex([2]int{center[0] + corner[0], center[1] + corner[1]})
+	}
+	return face
+}
+
+/******************* Wrapped Geometry ********************/
+
+// Returns the shortest vector from one point to another, which on a wrapped
+// grid may cross a seam
+func (theGrid WingedGrid) Offset(from, to [3]float64) [3]float64 {
+	return vectorSub(theGrid.nearestCopy(to, from), from)
+}
+
+// Returns the coords of the corners of a face in order, each moved by whole
+// periods to be next to the one before it, so a face across the seam of a
+// wrapped grid is in one piece with its first corner in place
+func (theGrid WingedGrid) FaceCoords(faceIndex int32) ([][3]float64, error) {
+	if !indexInRange(faceIndex, len(theGrid.Faces)) {
+		return nil, fmt.Errorf("Face %d out of range", faceIndex)
+	}
+	var edges []int32 = theGrid.Faces[faceIndex].Edges
+	var coords [][3]float64 = make([][3]float64, len(edges))
+	for k, edgeIndex := range edges {
+		vertexIndex, err := theGrid.Edges[edgeIndex].FirstVertexForFace(faceIndex)
+		if err != nil {
+			return nil, err
+		}
+		var corner [3]float64 = theGrid.Vertices[vertexIndex].Coords
+		if k > 0 {
+			corner = theGrid.nearestCopy(corner, coords[k-1])
+		}
+		coords[k] = corner
+	}
+	return coords, nil
+}
+
+// Returns the copy of the point, moved by whole periods, nearest to another
+func (theGrid WingedGrid) nearestCopy(point, near [3]float64) [3]float64 {
+	for axis, period := range theGrid.Period {
+		if period > 0 {
+			point[axis] = point[axis] - period*math.Round((point[axis]-near[axis])/period)
+		}
+	}
+	return point
+}
+
+// Returns the point moved by whole periods into [0, period) on each wrapped
+// axis, where the vertex coords of the planar maps are
+func (theGrid WingedGrid) wrapCoords(point [3]float64) [3]float64 {
+	for axis, period := range theGrid.Period {
+		if period > 0 {
+			point[axis] = point[axis] - period*math.Floor(point[axis]/period)
+		}
+	}
+	return point
+}
+
+// Returns an error for the first face across a seam of a wrapped grid, which
+// can't be written with shared vertices
+func (theGrid WingedGrid) checkNoSeamFaces(faceVertices [][]int32) error {
+	if theGrid.Period == [3]float64{} {
+		return nil
+	}
+	for faceIndex, vertices := range faceVertices {
+		for k := 1; k < len(vertices); k++ {
+			var previous, corner [3]float64 = theGrid.Vertices[vertices[k-1]].Coords, theGrid.Vertices[vertices[k]].Coords
+			if theGrid.nearestCopy(corner, previous) != corner {
+				return fmt.Errorf("Face %d crosses the seam of a wrapped grid", faceIndex)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,251 @@
+package wingedGrid
+
+import (
+	"bytes"
+	"math"
+	"testing"
+)
+
+// checks a planar map is valid, faces +z, and has the expected counts
+func checkPlanarMap(t *testing.T, name string, grid WingedGrid, vertices, faces, euler, boundaryEdges int) {
+	if len(grid.Vertices) != vertices || len(grid.Faces) != faces {
+		t.Errorf("%s: expected %d vertices and %d faces, got %d and %d", name, vertices, faces, len(grid.Vertices), len(grid.Faces))
+	}
+	if got := len(grid.Vertices) - len(grid.Edges) + len(grid.Faces); got != euler {
+		t.Errorf("%s: expected Euler characteristic %d, got %d", name, euler, got)
+	}
+	var boundary int
+	for _, edge := range grid.Edges {
+		if edge.IsBoundary() {
+			boundary++
+		}
+	}
+	if boundary != boundaryEdges {
+		t.Errorf("%s: expected %d boundary edges, got %d", name, boundaryEdges, boundary)
+	}
+	if report := grid.Validate(ValidateOptions{}); !report.Valid() {
+		t.Errorf("%s: invalid: %s", name, report.Err())
+	}
+	for index, vertex := range grid.Vertices {
+		if vertex.Coords[2] != 0 {
+			t.Errorf("%s: vertex %d is off the plane", name, index)
+		}
+	}
+}
+
+func TestPlanarTriangleMap(t *testing.T) {
+	var columns, rows int = 5, 4
+	var cases = []struct {
+		wrap                           PlanarWrap
+		vertices, euler, boundaryEdges int
+	}{
+		{NoWrap, (columns + 1) * (rows + 1), 1, 2*columns + 2*rows},
+		{WrapX, columns * (rows + 1), 0, 2 * columns},
+		{WrapY, (columns + 1) * rows, 0, 2 * rows},
+		{WrapXY, columns * rows, 0, 0},
+	}
+	for _, c := range cases {
+		grid, err := PlanarTriangleMap(int32(columns), int32(rows), c.wrap)
+		if err != nil {
+			t.Fatalf("Failed to create triangle map with wrap %d: %s", c.wrap, err)
+		}
+		checkPlanarMap(t, "triangle map", grid, c.vertices, 2*columns*rows, c.euler, c.boundaryEdges)
+		if c.wrap == NoWrap {
+			for index := range grid.Faces {
+				normal, _, _ := grid.faceNormalAndCenter(int32(index))
+				if normal[2] <= 0 {
+					t.Errorf("Face %d doesn't face +z", index)
+				}
+			}
+			for index, edge := range grid.Edges {
+				var a, b [3]float64 = grid.Vertices[edge.FirstVertexA].Coords, grid.Vertices[edge.FirstVertexB].Coords
+				if math.Abs(math.Hypot(a[0]-b[0], a[1]-b[1])-1) > 1e-12 {
+					t.Errorf("Edge %d is not one unit long", index)
+				}
+			}
+		}
+		if c.wrap == WrapXY {
+			for index, vertex := range grid.Vertices {
+				if len(vertex.Edges) != 6 {
+					t.Errorf("Vertex %d has %d edges, expected 6", index, len(vertex.Edges))
+				}
+			}
+		}
+	}
+}
+
+func TestPlanarHexMap(t *testing.T) {
+	var columns, rows int = 4, 6
+	var cases = []struct {
+		wrap                 PlanarWrap
+		euler, boundaryEdges int
+	}{
+		{NoWrap, 1, 4*columns + 4*rows - 2},
+		{WrapX, 0, 4 * columns},
+		{WrapY, 0, 4 * rows},
+		{WrapXY, 0, 0},
+	}
+	for _, c := range cases {
+		grid, err := PlanarHexMap(int32(columns), int32(rows), c.wrap)
+		if err != nil {
+			t.Fatalf("Failed to create hex map with wrap %d: %s", c.wrap, err)
+		}
+		// from the Euler characteristic, checked separately by the edge count
+		var vertices int = len(grid.Edges) - len(grid.Faces) + c.euler
+		checkPlanarMap(t, "hex map", grid, vertices, columns*rows, c.euler, c.boundaryEdges)
+		for index, face := range grid.Faces {
+			if len(face.Edges) != 6 {
+				t.Errorf("Face %d has %d edges, expected 6", index, len(face.Edges))
+			}
+		}
+		if c.wrap == WrapXY {
+			if len(grid.Vertices) != 2*columns*rows {
+				t.Errorf("Expected %d vertices, got %d", 2*columns*rows, len(grid.Vertices))
+			}
+			for index := range grid.Faces {
+				neighbors, _ := grid.NeighborsForFace(int32(index))
+				if len(neighbors) != 6 {
+					t.Errorf("Face %d has %d neighbors, expected 6", index, len(neighbors))
+				}
+			}
+		}
+	}
+	// the second hexagon of the second row is right of the first of the first
+	grid, _ := PlanarHexMap(int32(columns), int32(rows), NoWrap)
+	first, _ := grid.FaceCenter(0)
+	second, _ := grid.FaceCenter(int32(columns + 1))
+	if math.Abs(second[0]-first[0]-1.5*math.Sqrt(3)) > 1e-12 || math.Abs(second[1]-first[1]-1.5) > 1e-12 {
+		t.Errorf("Unexpected offset from %v to %v", first, second)
+	}
+}
+
+func TestPlanarHexagons(t *testing.T) {
+	for _, radius := range []int{1, 2, 5} {
+		grid, err := PlanarTriangleHexagon(int32(radius))
+		if err != nil {
+			t.Fatalf("Failed to create triangle hexagon: %s", err)
+		}
+		checkPlanarMap(t, "triangle hexagon", grid, 3*radius*(radius+1)+1, 6*radius*radius, 1, 6*radius)
+
+		grid, err = PlanarHexHexagon(int32(radius))
+		if err != nil {
+			t.Fatalf("Failed to create hex hexagon: %s", err)
+		}
+		var faces int = 3*radius*(radius+1) + 1
+		checkPlanarMap(t, "hex hexagon", grid, 6*(radius+1)*(radius+1), faces, 1, 6*(2*radius+1))
+		center, _ := grid.FaceCenter(int32(faces / 2))
+		if math.Hypot(center[0], center[1]) > 1e-12 {
+			t.Errorf("Middle hexagon is centered at %v", center)
+		}
+		neighbors, _ := grid.NeighborsForFace(int32(faces / 2))
+		if len(neighbors) != 6 {
+			t.Errorf("Middle hexagon has %d neighbors, expected 6", len(neighbors))
+		}
+	}
+}
+
+func TestPlanarMapSmallestWraps(t *testing.T) {
+	for _, wrap := range []PlanarWrap{WrapX, WrapY, WrapXY} {
+		grid, err := PlanarTriangleMap(3, 4, wrap)
+		if err != nil {
+			t.Fatalf("Failed to create triangle map with wrap %d: %s", wrap, err)
+		}
+		if report := grid.Validate(ValidateOptions{}); !report.Valid() {
+			t.Errorf("Triangle map with wrap %d invalid: %s", wrap, report.Err())
+		}
+		grid, err = PlanarHexMap(2, 2, wrap)
+		if err != nil {
+			t.Fatalf("Failed to create hex map with wrap %d: %s", wrap, err)
+		}
+		if report := grid.Validate(ValidateOptions{}); !report.Valid() {
+			t.Errorf("Hex map with wrap %d invalid: %s", wrap, report.Err())
+		}
+	}
+}
+
+func TestPlanarMapInvalid(t *testing.T) {
+	if _, err := PlanarTriangleMap(2, 4, WrapX); err == nil {
+		t.Error("Expected an error for two wrapped columns")
+	}
+	if _, err := PlanarTriangleMap(3, 5, WrapY); err == nil {
+		t.Error("Expected an error for an odd number of wrapped rows")
+	}
+	if _, err := PlanarHexMap(0, 2, NoWrap); err == nil {
+		t.Error("Expected an error for no columns")
+	}
+	if _, err := PlanarHexMap(2, 2, 4); err == nil {
+		t.Error("Expected an error for an unknown wrap")
+	}
+	if _, err := PlanarTriangleHexagon(0); err == nil {
+		t.Error("Expected an error for radius 0")
+	}
+}
+
+func TestPlanarMapWrappedGeometry(t *testing.T) {
+	var columns, rows int32 = 5, 4
+	triangles, _ := PlanarTriangleMap(columns, rows, WrapXY)
+	hexes, _ := PlanarHexMap(columns, rows, WrapXY)
+	var cases = []struct {
+		name           string
+		grid           WingedGrid
+		period         [3]float64
+		centerDistance float64
+	}{
+		{"triangle map", triangles, [3]float64{5, 4 * math.Sqrt(3) / 2, 0}, 1 / math.Sqrt(3)},
+		{"hex map", hexes, [3]float64{5 * math.Sqrt(3), 4 * 1.5, 0}, math.Sqrt(3)},
+	}
+	for _, c := range cases {
+		for axis := range c.period {
+			if math.Abs(c.grid.Period[axis]-c.period[axis]) > 1e-12 {
+				t.Fatalf("%s: expected period %v, got %v", c.name, c.period, c.grid.Period)
+			}
+		}
+		for index, edge := range c.grid.Edges {
+			var offset [3]float64 = c.grid.Offset(c.grid.Vertices[edge.FirstVertexA].Coords, c.grid.Vertices[edge.FirstVertexB].Coords)
+			if math.Abs(vectorLength(offset)-1) > 1e-12 {
+				t.Errorf("%s: edge %d is %f long across the seams", c.name, index, vectorLength(offset))
+			}
+		}
+		for index := range c.grid.Faces {
+			coords, err := c.grid.FaceCoords(int32(index))
+			if err != nil {
+				t.Fatalf("%s: face %d: %s", c.name, index, err)
+			}
+			for k, corner := range coords {
+				if math.Abs(distanceBetween3Points(corner, coords[(k+1)%len(coords)])-1) > 1e-12 {
+					t.Errorf("%s: face %d isn't in one piece: %v", c.name, index, coords)
+					break
+				}
+			}
+			center, _ := c.grid.FaceCenter(int32(index))
+			for axis := 0; axis < 2; axis++ {
+				if center[axis] < 0 || center[axis] >= c.period[axis] {
+					t.Errorf("%s: face %d center %v is outside the period", c.name, index, center)
+				}
+			}
+			neighbors, _ := c.grid.NeighborsForFace(int32(index))
+			for _, neighbor := range neighbors {
+				other, _ := c.grid.FaceCenter(neighbor)
+				if math.Abs(vectorLength(c.grid.Offset(center, other))-c.centerDistance) > 1e-12 {
+					t.Errorf("%s: faces %d and %d are %f apart", c.name, index, neighbor, vectorLength(c.grid.Offset(center, other)))
+				}
+			}
+		}
+
+		var buffer bytes.Buffer
+		if err := c.grid.WriteOBJ(&buffer); err == nil {
+			t.Errorf("%s: expected an error writing faces across the seam", c.name)
+		}
+		if err := c.grid.WriteGLB(&buffer, GLBOptions{}); err == nil {
+			t.Errorf("%s: expected an error writing faces across the seam", c.name)
+		}
+	}
+	flat, _ := PlanarHexMap(columns, rows, NoWrap)
+	if flat.Period != [3]float64{} {
+		t.Errorf("Expected no period without wrapping, got %v", flat.Period)
+	}
+	var buffer bytes.Buffer
+	if err := flat.WriteOBJ(&buffer); err != nil {
+		t.Errorf("Failed to write an unwrapped map: %s", err)
+	}
+}
@@ -0,0 +1,75 @@
+package wingedGrid
+
+// Base grids for the other triangle faced platonic solids, built with the same
+// conventions as BaseIcosahedron so they can be subdivided in the same way.
+
+// Sets up a tetrahedron from alternate corners of the cube with corners at
+// ±1, resulting in edges of length 2√2
+func BaseTetrahedron() (WingedGrid, error) {
+	var coords [][3]float64 = [][3]float64{
+		{1, 1, 1},   // 0
+		{1, -1, -1}, // 1
+		{-1, 1, -1}, // 2
+		{-1, -1, 1}, // 3
+	}
+	// counter-clockwise seen from outside
+	var faces [][]int32 = [][]int32{
+		{0, 1, 2},
+		{0, 2, 3},
+		{0, 3, 1},
+		{1, 3, 2},
+	}
+	return NewGridFromPolygons(coords, faces)
+}
+
+// Sets up an octahedron with its vertices on the unit axes, resulting in edges
+// of length √2. Each face covers one octant, so with +z as north each face is
+// a quarter of a hemisphere split at longitudes 0, 90, 180 and -90.
+func BaseOctahedron() (WingedGrid, error) {
+	var coords [][3]float64 = [][3]float64{
+		{1, 0, 0},  // 0
+		{0, 1, 0},  // 1
+		{-1, 0, 0}, // 2
+		{0, -1, 0}, // 3
+		{0, 0, 1},  // 4, north
+		{0, 0, -1}, // 5, south
+	}
+	// counter-clockwise seen from outside, northern faces first, in order of
+	// increasing longitude
+	var faces [][]int32 = [][]int32{
+		{0, 1, 4},
+		{1, 2, 4},
+		{2, 3, 4},
+		{3, 0, 4},
+		{1, 0, 5},
+		{2, 1, 5},
+		{3, 2, 5},
+		{0, 3, 5},
+	}
+	return NewGridFromPolygons(coords, faces)
+}
+
+// Sets up a cube with corners at ±1, resulting in edges of length 2, for
+// subdividing into a cube sphere with SubdivideQuads
+func BaseCube() (WingedGrid, error) {
+	var coords [][3]float64 = [][3]float64{
+		{-1, -1, -1}, // 0
+		{1, -1, -1},  // 1
+		{1, 1, -1},   // 2
+		{-1, 1, -1},  // 3
+		{-1, -1, 1},  // 4
+		{1, -1, 1},   // 5
+		{1, 1, 1},    // 6
+		{-1, 1, 1},   // 7
+	}
+	// counter-clockwise seen from outside
+	var faces [][]int32 = [][]int32{
+		{0, 3, 2, 1}, // -z
+		{4, 5, 6, 7}, // +z
+		{0, 1, 5, 4}, // -y
+		{1, 2, 6, 5}, // +x
+		{2, 3, 7, 6}, // +y
+		{3, 0, 4, 7}, // -x
+	}
+	return NewGridFromPolygons(coords, faces)
+}
@@ -0,0 +1,77 @@
+package wingedGrid
+
+import (
+	"math"
+	"testing"
+)
+
+func TestBasePlatonicGrids(t *testing.T) {
+	var cases = []struct {
+		name                       string
+		create                     func() (WingedGrid, error)
+		faces, edges, vertexDegree int
+		edgeLength                 float64
+	}{
+		{"tetrahedron", BaseTetrahedron, 4, 6, 3, 2 * math.Sqrt2},
+		{"octahedron", BaseOctahedron, 8, 12, 4, math.Sqrt2},
+	}
+	for _, c := range cases {
+		grid, err := c.create()
+		if err != nil {
+			t.Fatalf("Failed to create %s: %s", c.name, err)
+		}
+		if len(grid.Faces) != c.faces || len(grid.Edges) != c.edges {
+			t.Errorf("%s has %d faces and %d edges, expected %d and %d", c.name, len(grid.Faces), len(grid.Edges), c.faces, c.edges)
+		}
+		if report := grid.Validate(ValidateOptions{Spherical: true}); !report.Valid() {
+			t.Errorf("%s is invalid: %s", c.name, report.Err())
+		}
+		for index, vertex := range grid.Vertices {
+			if len(vertex.Edges) != c.vertexDegree {
+				t.Errorf("%s vertex %d has %d edges, expected %d", c.name, index, len(vertex.Edges), c.vertexDegree)
+			}
+		}
+		for index, edge := range grid.Edges {
+			var length float64 = distanceBetween3Points(grid.Vertices[edge.FirstVertexA].Coords, grid.Vertices[edge.FirstVertexB].Coords)
+			if (length-c.edgeLength)*(length-c.edgeLength) > tolerance {
+				t.Errorf("%s edge %d has length %f, expected %f", c.name, index, length, c.edgeLength)
+			}
+		}
+		for index := range grid.Faces {
+			correct, err := FaceOrientation(grid, int32(index), tolerance)
+			if err != nil || !correct {
+				t.Errorf("%s face %d incorrectly oriented: %v", c.name, index, err)
+			}
+		}
+
+		subdivided, err := grid.SubdivideTriangles(3)
+		if err != nil {
+			t.Fatalf("Failed to subdivide %s: %s", c.name, err)
+		}
+		if len(subdivided.Faces) != 16*c.faces || len(subdivided.Vertices) != 8*c.faces+2 {
+			t.Errorf("Subdivided %s has %d faces and %d vertices", c.name, len(subdivided.Faces), len(subdivided.Vertices))
+		}
+		if report := subdivided.Validate(ValidateOptions{Spherical: true}); !report.Valid() {
+			t.Errorf("Subdivided %s is invalid: %s", c.name, report.Err())
+		}
+	}
+}
+
+func TestBaseOctahedronOctants(t *testing.T) {
+	grid, err := BaseOctahedron()
+	if err != nil {
+		t.Fatalf("Failed to create octahedron: %s", err)
+	}
+	// the center of each face lies in its own octant
+	var seen map[[3]bool]bool = make(map[[3]bool]bool)
+	for index := range grid.Faces {
+		center, err := grid.FaceCenter(int32(index))
+		if err != nil {
+			t.Fatalf("Face %d: %s", index, err)
+		}
+		seen[[3]bool{center[0] > 0, center[1] > 0, center[2] > 0}] = true
+	}
+	if len(seen) != 8 {
+		t.Errorf("Faces cover %d octants, expected 8", len(seen))
+	}
+}
@@ -0,0 +1,224 @@
+package wingedGrid
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Stanford PLY export, with optional per-vertex and per-face data.
+//
+// Vertices are written as double x, y, z, faces as a uchar counted int list of
+// vertex indices in clockwise order. Scalars are written as doubles and colours
+// as uchar red, green, blue, both after the fixed properties.
+
+type PLYFormat int
+
+const (
+	PLYASCII PLYFormat = iota
+	PLYBinaryLittleEndian
+)
+
+// a named value for each vertex or each face
+type PLYScalar struct {
+	Name   string
+	Values []float64
+}
+
+// options for WingedGrid.WritePLY
+type PLYOptions struct {
+	Format PLYFormat
+	// write nx, ny, nz for each vertex, see OBJOptions.Normals
+	Normals bool
+	// one value per vertex, in vertex order
+	VertexScalars []PLYScalar
+	// one value per face, in face order
+	FaceScalars []PLYScalar
+	// nil, or one colour per vertex
+	VertexColors [][3]uint8
+	// nil, or one colour per face
+	FaceColors [][3]uint8
+}
+
+// properties of each element a scalar can't be named, as they may already be
+// written
+var plyVertexReservedNames = []string{"x", "y", "z", "nx", "ny", "nz", "red", "green", "blue"}
+var plyFaceReservedNames = []string{"vertex_indices", "red", "green", "blue"}
+
+// Writes the grid as a PLY mesh with one polygon per face
+func (theGrid WingedGrid) WritePLY(w io.Writer, options PLYOptions) error {
+	if options.Format != PLYASCII && options.Format != PLYBinaryLittleEndian {
+		return errors.New("Unknown PLY format")
+	}
+	err := checkPLYChannels("vertex", len(theGrid.Vertices), plyVertexReservedNames, options.VertexScalars, options.VertexColors)
+	if err != nil {
+		return err
+	}
+	err = checkPLYChannels("face", len(theGrid.Faces), plyFaceReservedNames, options.FaceScalars, options.FaceColors)
+	if err != nil {
+		return err
+	}
+	faceVertices, err := theGrid.faceVertexLists()
+	if err != nil {
+		return err
+	}
+	err = theGrid.checkNoSeamFaces(faceVertices)
+	if err != nil {
+		return err
+	}
+	for faceIndex, vertices := range faceVertices {
+		if len(vertices) > math.MaxUint8 {
+			return fmt.Errorf("Face %d has too many vertices for PLY", faceIndex)
+		}
+	}
+	var normals [][3]float64
+	if options.Normals {
+		normals = theGrid.vertexNormals(faceVertices)
+	}
+
+	var out *bufio.Writer = bufio.NewWriter(w)
+	writePLYHeader(out, len(theGrid.Vertices), len(theGrid.Faces), options)
+
+	var element *plyElementWriter = &plyElementWriter{out: out, binary: options.Format == PLYBinaryLittleEndian}
+	for index, vertex := range theGrid.Vertices {
+		element.doubles(vertex.Coords[:]...)
+		if options.Normals {
+			element.doubles(normals[index][:]...)
+		}
+		for _, scalar := range options.VertexScalars {
+			element.doubles(scalar.Values[index])
+		}
+		if options.VertexColors != nil {
+			element.bytes(options.VertexColors[index][:]...)
+		}
+		element.end()
+	}
+	for index, vertices := range faceVertices {
+		element.bytes(uint8(len(vertices)))
+		element.ints(vertices...)
+		for _, scalar := range options.FaceScalars {
+			element.doubles(scalar.Values[index])
+		}
+		if options.FaceColors != nil {
+			element.bytes(options.FaceColors[index][:]...)
+		}
+		element.end()
+	}
+	return out.Flush()
+}
+
+// Returns an error if a channel has the wrong length or an unusable name
+func checkPLYChannels(element string, count int, reservedNames []string, scalars []PLYScalar, colors [][3]uint8) error {
+	var names map[string]bool = make(map[string]bool)
+	for _, reserved := range reservedNames {
+		names[reserved] = true
+	}
+	for _, scalar := range scalars {
+		if scalar.Name == "" || strings.ContainsAny(scalar.Name, " \t\r\n") {
+			return fmt.Errorf("Invalid %s scalar name %q", element, scalar.Name)
+		}
+		if names[scalar.Name] {
+			return fmt.Errorf("Duplicate or reserved %s scalar name %q", element, scalar.Name)
+		}
+		names[scalar.Name] = true
+		if len(scalar.Values) != count {
+			return fmt.Errorf("%s scalar %q has %d values, expected %d", element, scalar.Name, len(scalar.Values), count)
+		}
+	}
+	if colors != nil && len(colors) != count {
+		return fmt.Errorf("%s colors has %d values, expected %d", element, len(colors), count)
+	}
+	return nil
+}
+
+func writePLYHeader(out *bufio.Writer, vertexCount, faceCount int, options PLYOptions) {
+	out.WriteString("ply\n")
+	if options.Format == PLYBinaryLittleEndian {
+		out.WriteString("format binary_little_endian 1.0\n")
+	} else {
+		out.WriteString("format ascii 1.0\n")
+	}
+	out.WriteString("comment WingedGrid\n")
+	fmt.Fprintf(out, "element vertex %d\n", vertexCount)
+	out.WriteString("property double x\nproperty double y\nproperty double z\n")
+	if options.Normals {
+		out.WriteString("property double nx\nproperty double ny\nproperty double nz\n")
+	}
+	for _, scalar := range options.VertexScalars {
+		fmt.Fprintf(out, "property double %s\n", scalar.Name)
+	}
+	if options.VertexColors != nil {
+		out.WriteString("property uchar red\nproperty uchar green\nproperty uchar blue\n")
+	}
+	fmt.Fprintf(out, "element face %d\n", faceCount)
+	out.WriteString("property list uchar int vertex_indices\n")
+	for _, scalar := range options.FaceScalars {
+		fmt.Fprintf(out, "property double %s\n", scalar.Name)
+	}
+	if options.FaceColors != nil {
+		out.WriteString("property uchar red\nproperty uchar green\nproperty uchar blue\n")
+	}
+	out.WriteString("end_header\n")
+}
+
+// writes the properties of one element, space separated lines for ascii
+type plyElementWriter struct {
+	out     *bufio.Writer
+	binary  bool
+	started bool
+	scratch [8]byte
+}
+
+func (element *plyElementWriter) separate() {
+	if element.started {
+		element.out.WriteByte(' ')
+	}
+	element.started = true
+}
+
+func (element *plyElementWriter) doubles(values ...float64) {
+	for _, value := range values {
+		if element.binary {
+			binary.LittleEndian.PutUint64(element.scratch[:], math.Float64bits(value))
+			element.out.Write(element.scratch[:8])
+		} else {
+			element.separate()
+			element.out.WriteString(strconv.FormatFloat(value, 'g', -1, 64))
+		}
+	}
+}
+
+func (element *plyElementWriter) ints(values ...int32) {
+	for _, value := range values {
+		if element.binary {
+			binary.LittleEndian.PutUint32(element.scratch[:], uint32(value))
+			element.out.Write(element.scratch[:4])
+		} else {
+			element.separate()
+			element.out.WriteString(strconv.FormatInt(int64(value), 10))
+		}
+	}
+}
+
+func (element *plyElementWriter) bytes(values ...uint8) {
+	for _, value := range values {
+		if element.binary {
+			element.out.WriteByte(value)
+		} else {
+			element.separate()
+			element.out.WriteString(strconv.FormatUint(uint64(value), 10))
+		}
+	}
+}
+
+func (element *plyElementWriter) end() {
+	if !element.binary {
+		element.out.WriteByte('\n')
+	}
+	element.started = false
+}
@@ -0,0 +1,104 @@
+package wingedGrid
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestPLYASCII(t *testing.T) {
+	base, _ := BaseIcosahedron()
+	dual, _ := base.CreateDual()
+	elevation := make([]float64, len(dual.Faces))
+	colors := make([][3]uint8, len(dual.Faces))
+	for i := range elevation {
+		elevation[i] = float64(i) / 4
+		colors[i] = [3]uint8{uint8(i), 0, 255}
+	}
+	var buffer bytes.Buffer
+	err := dual.WritePLY(&buffer, PLYOptions{
+		FaceScalars: []PLYScalar{{Name: "elevation", Values: elevation}},
+		FaceColors:  colors,
+	})
+	if err != nil {
+		t.Fatalf("Failed to write PLY: %s", err)
+	}
+	parts := strings.SplitN(buffer.String(), "end_header\n", 2)
+	if !strings.Contains(parts[0], "element vertex 20\n") || !strings.Contains(parts[0], "element face 12\n") {
+		t.Fatalf("Unexpected header:\n%s", parts[0])
+	}
+	if !strings.Contains(parts[0], "property double elevation\n") {
+		t.Errorf("Missing scalar property in header:\n%s", parts[0])
+	}
+	lines := strings.Split(strings.TrimSpace(parts[1]), "\n")
+	if len(lines) != 32 {
+		t.Fatalf("Expected 32 element lines, got %d", len(lines))
+	}
+	// pentagons: count, five indices, elevation, colour
+	last := strings.Fields(lines[31])
+	if len(last) != 10 || last[0] != "5" || last[6] != "2.75" || last[7] != "11" || last[9] != "255" {
+		t.Errorf("Unexpected face line: %s", lines[31])
+	}
+}
+
+func TestPLYBinary(t *testing.T) {
+	grid, _ := BaseIcosahedron()
+	heights := make([]float64, len(grid.Vertices))
+	for i := range heights {
+		heights[i] = float64(i)
+	}
+	var buffer bytes.Buffer
+	err := grid.WritePLY(&buffer, PLYOptions{
+		Format:        PLYBinaryLittleEndian,
+		Normals:       true,
+		VertexScalars: []PLYScalar{{Name: "height", Values: heights}},
+	})
+	if err != nil {
+		t.Fatalf("Failed to write PLY: %s", err)
+	}
+	parts := bytes.SplitN(buffer.Bytes(), []byte("end_header\n"), 2)
+	body := parts[1]
+	// 7 doubles per vertex, count and three ints per face
+	if len(body) != 12*7*8+20*(1+3*4) {
+		t.Fatalf("Unexpected body size %d", len(body))
+	}
+	last := math.Float64frombits(binary.LittleEndian.Uint64(body[11*56+48:]))
+	if last != 11 {
+		t.Errorf("Expected height 11 for last vertex, got %f", last)
+	}
+	if body[12*56] != 3 {
+		t.Errorf("Expected triangle count, got %d", body[12*56])
+	}
+}
+
+func TestPLYRejectsBadChannels(t *testing.T) {
+	grid, _ := BaseIcosahedron()
+	var buffer bytes.Buffer
+	bad := []PLYOptions{
+		{VertexScalars: []PLYScalar{{Name: "short", Values: []float64{1}}}},
+		{FaceScalars: []PLYScalar{{Name: "vertex_indices", Values: make([]float64, 20)}}},
+		{VertexScalars: []PLYScalar{{Name: "nx", Values: make([]float64, 12)}}},
+		{FaceScalars: []PLYScalar{{Name: "two words", Values: make([]float64, 20)}}},
+		{VertexColors: make([][3]uint8, 3)},
+		{Format: PLYFormat(7)},
+	}
+	for _, options := range bad {
+		if err := grid.WritePLY(&buffer, options); err == nil {
+			t.Errorf("Expected an error for options %+v", options)
+		}
+	}
+}
+
+func TestPLYFaceScalarsNamedLikeVertexProperties(t *testing.T) {
+	grid, _ := BaseIcosahedron()
+	var buffer bytes.Buffer
+	err := grid.WritePLY(&buffer, PLYOptions{FaceScalars: []PLYScalar{{Name: "x", Values: make([]float64, 20)}, {Name: "nz", Values: make([]float64, 20)}}})
+	if err != nil {
+		t.Fatalf("Failed to write PLY: %s", err)
+	}
+	if !strings.Contains(buffer.String(), "element face 20\nproperty list uchar int vertex_indices\nproperty double x\nproperty double nz\n") {
+		t.Errorf("Face scalars missing from the header")
+	}
+}
@@ -0,0 +1,140 @@
+package wingedGrid
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Construction of a WingedGrid from a face-vertex mesh, the common base for
+// importers and new primitives.
+
+var (
+	// an edge is shared by more than two faces, a face uses an edge twice, or
+	// the faces around a vertex don't form a single fan
+	ErrNonManifold = errors.New("non-manifold input")
+	// two faces sharing an edge traverse it in the same direction
+	ErrInconsistentOrientation = errors.New("inconsistently oriented input")
+	// an operation that needs a closed surface was given one with a boundary
+	ErrOpenSurface = errors.New("surface is not closed")
+)
+
+// identifies an edge by its vertices, lowest index first
+type vertexPair struct {
+	low, high int32
+}
+
+func newVertexPair(first, second int32) vertexPair {
+	if first < second {
+		return vertexPair{first, second}
+	}
+	return vertexPair{second, first}
+}
+
+// Builds a fully linked grid from vertex coords and faces given as lists of
+// vertex indices in clockwise order, the order used by the faces of a
+// WingedGrid (counter-clockwise seen from outside, as in most mesh formats).
+// Each pair of consecutive vertices in a face becomes an edge, which may be
+// shared with one other face traversing it in the opposite direction. Edges
+// used by a single face are on the boundary, with FaceB set to NoFace.
+//
+// Faces keep their index, and each face's edges start with the edge from its
+// first vertex. Vertices keep their index and coords. Edges are numbered in
+// the order they are first found.
+//
+// Errors wrap ErrNonManifold or ErrInconsistentOrientation when the faces
+// don't form an oriented surface.
+func NewGridFromPolygons(coords [][3]float64, faces [][]int32) (WingedGrid, error) {
+	var theGrid WingedGrid
+	if len(faces) == 0 {
+		return theGrid, errors.New("No faces given")
+	}
+	if len(coords) > maxGridElements || len(faces) > maxGridElements {
+		return theGrid, errors.New("Too many elements for int32 indices")
+	}
+
+	theGrid.Faces = make([]WingedFace, len(faces))
+	var edgeIndices map[vertexPair]int32 = make(map[vertexPair]int32)
+	for faceIndex, faceVertices := range faces {
+		if len(faceVertices) < 3 {
+			return theGrid, fmt.Errorf("Face %d has %d vertices", faceIndex, len(faceVertices))
+		}
+		theGrid.Faces[faceIndex].Edges = make([]int32, len(faceVertices))
+		for i, first := range faceVertices {
+			var second int32 = faceVertices[(i+1)%len(faceVertices)]
+			if !indexInRange(first, len(coords)) {
+				return theGrid, fmt.Errorf("Face %d has vertex %d out of range", faceIndex, first)
+			}
+			if first == second {
+				return theGrid, fmt.Errorf("Face %d repeats vertex %d", faceIndex, first)
+			}
+			var pair vertexPair = newVertexPair(first, second)
+			edgeIndex, found := edgeIndices[pair]
+			if !found {
+				// first face to use the edge is face A
+				edgeIndex = int32(len(theGrid.Edges))
+				edgeIndices[pair] = edgeIndex
+				theGrid.Edges = append(theGrid.Edges, WingedEdge{
+					FirstVertexA: first, FirstVertexB: second,
+					FaceA: int32(faceIndex), FaceB: NoFace,
+					PrevA: -1, NextA: -1, PrevB: NoEdge, NextB: NoEdge,
+				})
+			} else {
+				var theEdge *WingedEdge = &theGrid.Edges[edgeIndex]
+				if theEdge.FaceA == int32(faceIndex) || theEdge.FaceB != NoFace {
+					return theGrid, fmt.Errorf("%w: edge between vertices %d and %d is used by faces %d, %d and %d", ErrNonManifold, first, second, theEdge.FaceA, theEdge.FaceB, faceIndex)
+				}
+				if theEdge.FirstVertexA == first {
+					return theGrid, fmt.Errorf("%w: faces %d and %d traverse the edge from vertex %d to %d in the same direction", ErrInconsistentOrientation, theEdge.FaceA, faceIndex, first, second)
+				}
+				theEdge.FaceB = int32(faceIndex)
+			}
+			theGrid.Faces[faceIndex].Edges[i] = edgeIndex
+		}
+	}
+
+	theGrid.linkFaceEdges()
+
+	theGrid.Vertices = make([]WingedVertex, len(coords))
+	var used []bool = make([]bool, len(coords))
+	for index := range coords {
+		theGrid.Vertices[index].Coords = coords[index]
+	}
+	for _, edge := range theGrid.Edges {
+		used[edge.FirstVertexA] = true
+		used[edge.FirstVertexB] = true
+	}
+	for index := range used {
+		if !used[index] {
+			return theGrid, fmt.Errorf("Vertex %d is not used by any face", index)
+		}
+	}
+	// a vertex whose edges don't form a single loop or open fan is where
+	// separate fans touch
+	vertexEdges, err := theGrid.vertexEdgeLoops()
+	if err != nil {
+		return theGrid, fmt.Errorf("%w: %s", ErrNonManifold, err)
+	}
+	theGrid.setVertexEdges(vertexEdges)
+	return theGrid, nil
+}
+
+// the most faces, edges or vertices addressable with int32 indices
+const maxGridElements = 1<<31 - 1
+
+// Sets prev and next of every edge from the order of the face edge lists
+func (theGrid WingedGrid) linkFaceEdges() {
+	for faceIndex, face := range theGrid.Faces {
+		for i, edgeIndex := range face.Edges {
+			var prev int32 = face.Edges[(i+len(face.Edges)-1)%len(face.Edges)]
+			var next int32 = face.Edges[(i+1)%len(face.Edges)]
+			var theEdge *WingedEdge = &theGrid.Edges[edgeIndex]
+			if theEdge.FaceA == int32(faceIndex) {
+				theEdge.PrevA = prev
+				theEdge.NextA = next
+			} else {
+				theEdge.PrevB = prev
+				theEdge.NextB = next
+			}
+		}
+	}
+}
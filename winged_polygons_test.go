@@ -0,0 +1,219 @@
+package wingedGrid
+
+import (
+	"errors"
+	"testing"
+)
+
+// the faces of a grid as vertex lists, for rebuilding it
+func gridPolygons(t *testing.T, theGrid WingedGrid) ([][3]float64, [][]int32) {
+	var coords [][3]float64 = make([][3]float64, len(theGrid.Vertices))
+	for index, vertex := range theGrid.Vertices {
+		coords[index] = vertex.Coords
+	}
+	return coords, mustFaceVertexLists(t, theGrid)
+}
+
+// the faces of a grid with centers above the xy plane, as an open grid
+func northernPatch(t *testing.T, theGrid WingedGrid) WingedGrid {
+	coords, faces := gridPolygons(t, theGrid)
+	var newIndex map[int32]int32 = make(map[int32]int32)
+	var patchCoords [][3]float64
+	var patchFaces [][]int32
+	for _, face := range faces {
+		var z float64
+		for _, vertexIndex := range face {
+			z += coords[vertexIndex][2]
+		}
+		if z <= 0 {
+			continue
+		}
+		var patchFace []int32
+		for _, vertexIndex := range face {
+			index, ok := newIndex[vertexIndex]
+			if !ok {
+				index = int32(len(patchCoords))
+				newIndex[vertexIndex] = index
+				patchCoords = append(patchCoords, coords[vertexIndex])
+			}
+			patchFace = append(patchFace, index)
+		}
+		patchFaces = append(patchFaces, patchFace)
+	}
+	patch, err := NewGridFromPolygons(patchCoords, patchFaces)
+	if err != nil {
+		t.Fatalf("Failed to create patch: %s", err)
+	}
+	return patch
+}
+
+func TestGridFromPolygonsMatchesIcosahedron(t *testing.T) {
+	base, _ := BaseIcosahedron()
+	coords, faces := gridPolygons(t, base)
+	grid, err := NewGridFromPolygons(coords, faces)
+	if err != nil {
+		t.Fatalf("Failed to build grid: %s", err)
+	}
+	if len(grid.Faces) != 20 || len(grid.Edges) != 30 || len(grid.Vertices) != 12 {
+		t.Fatalf("Unexpected counts %d/%d/%d", len(grid.Faces), len(grid.Edges), len(grid.Vertices))
+	}
+	report := grid.Validate(ValidateOptions{Spherical: true})
+	if !report.Valid() {
+		t.Fatalf("Built grid invalid: %s", report.Err())
+	}
+	// same faces, starting from the same vertex
+	builtFaces := mustFaceVertexLists(t, grid)
+	for index := range faces {
+		if !int32SlicesEqual(faces[index], builtFaces[index]) {
+			t.Errorf("Face %d has vertices %v, expected %v", index, builtFaces[index], faces[index])
+		}
+	}
+	subdivided, err := grid.SubdivideTriangles(3)
+	if err != nil {
+		t.Fatalf("Failed to subdivide: %s", err)
+	}
+	if !subdivided.Validate(ValidateOptions{Spherical: true}).Valid() {
+		t.Error("Subdivision of built grid invalid")
+	}
+}
+
+func TestGridFromPolygonsQuads(t *testing.T) {
+	coords := [][3]float64{
+		{-1, -1, -1}, {1, -1, -1}, {1, 1, -1}, {-1, 1, -1},
+		{-1, -1, 1}, {1, -1, 1}, {1, 1, 1}, {-1, 1, 1},
+	}
+	faces := [][]int32{
+		{0, 3, 2, 1}, {4, 5, 6, 7},
+		{0, 1, 5, 4}, {1, 2, 6, 5},
+		{2, 3, 7, 6}, {3, 0, 4, 7},
+	}
+	cube, err := NewGridFromPolygons(coords, faces)
+	if err != nil {
+		t.Fatalf("Failed to build cube: %s", err)
+	}
+	if len(cube.Edges) != 12 {
+		t.Errorf("Expected 12 edges, got %d", len(cube.Edges))
+	}
+	report := cube.Validate(ValidateOptions{Spherical: true})
+	if !report.Valid() {
+		t.Errorf("Cube invalid: %s", report.Err())
+	}
+}
+
+func TestGridFromPolygonsErrors(t *testing.T) {
+	base, _ := BaseIcosahedron()
+	coords, faces := gridPolygons(t, base)
+
+	flipped := append([][]int32(nil), faces...)
+	flipped[4] = []int32{faces[4][2], faces[4][1], faces[4][0]}
+	_, err := NewGridFromPolygons(coords, flipped)
+	if !errors.Is(err, ErrInconsistentOrientation) {
+		t.Errorf("Expected an orientation error, got: %v", err)
+	}
+
+	// a third face on an existing edge
+	extra := append(append([][]int32(nil), faces...), []int32{faces[0][0], faces[0][1], faces[5][0]})
+	_, err = NewGridFromPolygons(coords, extra)
+	if !errors.Is(err, ErrNonManifold) {
+		t.Errorf("Expected a non-manifold error, got: %v", err)
+	}
+
+	// two octahedra sharing a single vertex
+	octahedron := [][]int32{
+		{0, 2, 4}, {2, 1, 4}, {1, 3, 4}, {3, 0, 4},
+		{2, 0, 5}, {1, 2, 5}, {3, 1, 5}, {0, 3, 5},
+	}
+	var pinched [][]int32
+	var pinchedCoords [][3]float64 = make([][3]float64, 11)
+	for _, face := range octahedron {
+		pinched = append(pinched, face)
+		var other []int32
+		for _, vertex := range face {
+			// vertex 0 is shared, the rest offset
+			if vertex != 0 {
+				vertex += 5
+			}
+			other = append(other, vertex)
+		}
+		pinched = append(pinched, other)
+	}
+	_, err = NewGridFromPolygons(pinchedCoords, pinched)
+	if !errors.Is(err, ErrNonManifold) {
+		t.Errorf("Expected a non-manifold vertex error, got: %v", err)
+	}
+
+	_, err = NewGridFromPolygons(append(coords, [3]float64{}), faces)
+	if err == nil {
+		t.Error("Expected an error for an unused vertex")
+	}
+	_, err = NewGridFromPolygons(coords, [][]int32{{0, 1}})
+	if err == nil {
+		t.Error("Expected an error for a two vertex face")
+	}
+}
+
+func TestGridFromPolygonsOpenPatch(t *testing.T) {
+	base, _ := BaseIcosahedron()
+	subdivided, err := base.SubdivideTriangles(3)
+	if err != nil {
+		t.Fatalf("Failed to subdivide base icosahedron: %s", err)
+	}
+	patch := northernPatch(t, subdivided)
+	report := patch.Validate(ValidateOptions{Spherical: true})
+	if !report.Valid() {
+		t.Fatalf("Patch invalid: %s", report.Err())
+	}
+	// a disc
+	euler := len(patch.Vertices) - len(patch.Edges) + len(patch.Faces)
+	if euler != 1 {
+		t.Errorf("Expected Euler characteristic 1, got %d", euler)
+	}
+
+	var boundaryEdges int
+	var boundaryFaces map[int32]bool = make(map[int32]bool)
+	for _, edge := range patch.Edges {
+		if edge.IsBoundary() {
+			boundaryEdges++
+			boundaryFaces[edge.FaceA] = true
+			if edge.PrevB != NoEdge || edge.NextB != NoEdge {
+				t.Errorf("Boundary edge has wings %d, %d on the missing side", edge.PrevB, edge.NextB)
+			}
+		}
+	}
+	if boundaryEdges == 0 {
+		t.Fatal("Expected boundary edges")
+	}
+	for faceIndex := range boundaryFaces {
+		neighbors, err := patch.NeighborsForFace(faceIndex)
+		if err != nil {
+			t.Fatalf("Failed to get neighbors of face %d: %s", faceIndex, err)
+		}
+		if len(neighbors) >= 3 {
+			t.Errorf("Boundary face %d has %d neighbors", faceIndex, len(neighbors))
+		}
+	}
+
+	// every boundary vertex is an open fan with two boundary edges
+	var boundaryVertices int
+	for index, vertex := range patch.Vertices {
+		var last int32 = vertex.Edges[len(vertex.Edges)-1]
+		next, err := patch.Edges[last].NextEdgeForVertex(int32(index))
+		if err != nil {
+			t.Fatalf("Failed to walk vertex %d: %s", index, err)
+		}
+		if next != NoEdge {
+			continue
+		}
+		boundaryVertices++
+		prev, _ := patch.Edges[vertex.Edges[0]].PrevEdgeForVertex(int32(index))
+		if prev != NoEdge {
+			t.Errorf("Vertex %d fan starts after edge %d", index, prev)
+		}
+		if !patch.Edges[last].IsBoundary() || !patch.Edges[vertex.Edges[0]].IsBoundary() {
+			t.Errorf("Vertex %d fan doesn't end on boundary edges", index)
+		}
+	}
+	if boundaryVertices != boundaryEdges {
+		t.Errorf("Expected %d boundary vertices, got %d", boundaryEdges, boundaryVertices)
+	}
+}
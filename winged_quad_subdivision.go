@@ -0,0 +1,141 @@
+package wingedGrid
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Splits each quad face into a patchSize by patchSize patch of quads, for a
+// grid with only quad faces. Each edge is split into patchSize segments of
+// equal angle from the origin, so patchSize-1 vertices are added along each
+// edge, unlike the count given to SubdivideTriangles. Interior vertices blend
+// the corners bilinearly, with the mean of the angles of opposite edges. New
+// vertices are placed at the radius interpolated from the face corners, so
+// subdividing BaseCube gives a cube sphere, though not the equiangular
+// (gnomonic) one.
+//
+// The original vertices keep their index, followed by the vertices along each
+// old edge from FirstVertexA, then the interior vertices of each old face.
+// The patch of old face f is faces f*patchSize*patchSize on, in rows running
+// from the face's first vertex toward its second.
+func (oldGrid WingedGrid) SubdivideQuads(patchSize int32) (WingedGrid, error) {
+	if patchSize < 1 {
+		return WingedGrid{}, errors.New("Invalid patch size")
+	}
+	faceVertices, err := oldGrid.faceVertexLists()
+	if err != nil {
+		return WingedGrid{}, err
+	}
+	for faceIndex, vertices := range faceVertices {
+		if len(vertices) != 4 {
+			return WingedGrid{}, fmt.Errorf("Face %d has %d edges, expected a quad", faceIndex, len(vertices))
+		}
+	}
+	var n int = int(patchSize)
+	var vertexCount int = len(oldGrid.Vertices) + len(oldGrid.Edges)*(n-1) + len(oldGrid.Faces)*(n-1)*(n-1)
+	if vertexCount > maxGridElements || len(oldGrid.Faces)*n*n > maxGridElements {
+		return WingedGrid{}, errors.New("Too many elements for int32 indices")
+	}
+
+	var coords [][3]float64 = make([][3]float64, vertexCount)
+	for index, vertex := range oldGrid.Vertices {
+		coords[index] = vertex.Coords
+	}
+	var edgeOffset int = len(oldGrid.Vertices)
+	for edgeIndex, edge := range oldGrid.Edges {
+		var first, second [3]float64 = oldGrid.Vertices[edge.FirstVertexA].Coords, oldGrid.Vertices[edge.FirstVertexB].Coords
+		var angle float64 = vectorAngle(first, second)
+		for j := 1; j < n; j++ {
+			coords[edgeOffset+edgeIndex*(n-1)+j-1] = interpolateQuad(first, second, second, first, chordParameter(float64(j)/float64(n), angle), 0)
+		}
+	}
+
+	var faceOffset int = edgeOffset + len(oldGrid.Edges)*(n-1)
+	var faces [][]int32 = make([][]int32, 0, len(oldGrid.Faces)*n*n)
+	for faceIndex, vertices := range faceVertices {
+		var corners [4][3]float64
+		for k, vertexIndex := range vertices {
+			corners[k] = oldGrid.Vertices[vertexIndex].Coords
+		}
+		// patch point (i, j) is i steps from the first corner toward the
+		// second and j steps toward the fourth
+		var point = func(i, j int) int32 {
+			switch {
+			case j == 0:
+				return oldGrid.quadEdgeVertex(faceIndex, 0, i, n)
+			case i == n:
+				return oldGrid.quadEdgeVertex(faceIndex, 1, j, n)
+			case j == n:
+				return oldGrid.quadEdgeVertex(faceIndex, 2, n-i, n)
+			case i == 0:
+				return oldGrid.quadEdgeVertex(faceIndex, 3, n-j, n)
+			}
+			return int32(faceOffset + faceIndex*(n-1)*(n-1) + (j-1)*(n-1) + i - 1)
+		}
+		// opposite edges share a parameter, use the mean of their angles
+		var angleU float64 = (vectorAngle(corners[0], corners[1]) + vectorAngle(corners[3], corners[2])) / 2
+		var angleV float64 = (vectorAngle(corners[0], corners[3]) + vectorAngle(corners[1], corners[2])) / 2
+		for j := 1; j < n; j++ {
+			for i := 1; i < n; i++ {
+				coords[point(i, j)] = interpolateQuad(corners[0], corners[1], corners[2], corners[3],
+					chordParameter(float64(i)/float64(n), angleU), chordParameter(float64(j)/float64(n), angleV))
+			}
+		}
+		for j := 0; j < n; j++ {
+			for i := 0; i < n; i++ {
+				faces = append(faces, []int32{point(i, j), point(i+1, j), point(i+1, j+1), point(i, j+1)})
+			}
+		}
+	}
+	return NewGridFromPolygons(coords, faces)
+}
+
+// Returns the index of the vertex the given number of steps along a face's
+// edge, from the first vertex of the edge for the face
+func (oldGrid WingedGrid) quadEdgeVertex(faceIndex, edgeInFace, steps, n int) int32 {
+	var edge WingedEdge = oldGrid.Edges[oldGrid.Faces[faceIndex].Edges[edgeInFace]]
+	var forward bool = edge.FaceA == int32(faceIndex)
+	if steps == 0 {
+		if forward {
+			return edge.FirstVertexA
+		}
+		return edge.FirstVertexB
+	}
+	if steps == n {
+		if forward {
+			return edge.FirstVertexB
+		}
+		return edge.FirstVertexA
+	}
+	if !forward {
+		steps = n - steps
+	}
+	return int32(len(oldGrid.Vertices) + int(oldGrid.Faces[faceIndex].Edges[edgeInFace])*(n-1) + steps - 1)
+}
+
+// Returns the parameter along a chord spanning the given angle from the
+// origin at which the angle from its start is the fraction t of the whole
+func chordParameter(t, angle float64) float64 {
+	if angle < 1e-12 {
+		return t
+	}
+	var start, end float64 = math.Sin(t * angle), math.Sin((1 - t) * angle)
+	return start / (start + end)
+}
+
+// Returns the point at (u, v) of the flat bilinear patch over the corners,
+// scaled to the radius interpolated in the same way from the corner radii
+func interpolateQuad(c0, c1, c2, c3 [3]float64, u, v float64) [3]float64 {
+	var w0, w1, w2, w3 float64 = (1 - u) * (1 - v), u * (1 - v), u * v, (1 - u) * v
+	var point [3]float64
+	for k := 0; k < 3; k++ {
+		point[k] = w0*c0[k] + w1*c1[k] + w2*c2[k] + w3*c3[k]
+	}
+	var radius float64 = w0*vectorLength(c0) + w1*vectorLength(c1) + w2*vectorLength(c2) + w3*vectorLength(c3)
+	var length float64 = vectorLength(point)
+	if length == 0 {
+		return point
+	}
+	return [3]float64{point[0] * radius / length, point[1] * radius / length, point[2] * radius / length}
+}
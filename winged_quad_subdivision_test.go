@@ -0,0 +1,91 @@
+package wingedGrid
+
+import (
+	"math"
+	"testing"
+)
+
+func TestBaseCube(t *testing.T) {
+	cube, err := BaseCube()
+	if err != nil {
+		t.Fatalf("Failed to create cube: %s", err)
+	}
+	if len(cube.Faces) != 6 || len(cube.Edges) != 12 || len(cube.Vertices) != 8 {
+		t.Fatalf("Unexpected counts %d %d %d", len(cube.Faces), len(cube.Edges), len(cube.Vertices))
+	}
+	if report := cube.Validate(ValidateOptions{Spherical: true}); !report.Valid() {
+		t.Errorf("Cube is invalid: %s", report.Err())
+	}
+}
+
+func TestSubdivideQuadsCubeSphere(t *testing.T) {
+	cube, err := BaseCube()
+	if err != nil {
+		t.Fatalf("Failed to create cube: %s", err)
+	}
+	var n int = 4
+	sphere, err := cube.SubdivideQuads(int32(n))
+	if err != nil {
+		t.Fatalf("Failed to subdivide: %s", err)
+	}
+	if len(sphere.Faces) != 6*n*n || len(sphere.Vertices) != 6*n*n+2 || len(sphere.Edges) != 12*n*n {
+		t.Fatalf("Unexpected counts %d %d %d", len(sphere.Faces), len(sphere.Edges), len(sphere.Vertices))
+	}
+	if report := sphere.Validate(ValidateOptions{Spherical: true}); !report.Valid() {
+		t.Fatalf("Cube sphere is invalid: %s", report.Err())
+	}
+	for index, vertex := range sphere.Vertices {
+		if length := vectorLength(vertex.Coords); math.Abs(length-math.Sqrt(3)) > 1e-12 {
+			t.Errorf("Vertex %d is at radius %f", index, length)
+		}
+		if index < len(cube.Vertices) && vertex.Coords != cube.Vertices[index].Coords {
+			t.Errorf("Vertex %d moved", index)
+		}
+	}
+	for index, face := range sphere.Faces {
+		if len(face.Edges) != 4 {
+			t.Errorf("Face %d has %d edges", index, len(face.Edges))
+		}
+	}
+	// every edge along the old cube edges spans the same angle
+	var expected float64 = vectorAngle(cube.Vertices[0].Coords, cube.Vertices[1].Coords) / float64(n)
+	for oldEdgeIndex, oldEdge := range cube.Edges {
+		var previous [3]float64 = cube.Vertices[oldEdge.FirstVertexA].Coords
+		for j := 0; j < n; j++ {
+			var next [3]float64 = cube.Vertices[oldEdge.FirstVertexB].Coords
+			if j < n-1 {
+				next = sphere.Vertices[len(cube.Vertices)+oldEdgeIndex*(n-1)+j].Coords
+			}
+			if angle := vectorAngle(previous, next); math.Abs(angle-expected) > 1e-9 {
+				t.Errorf("Old edge %d segment %d spans %f, expected %f", oldEdgeIndex, j, angle, expected)
+			}
+			previous = next
+		}
+	}
+	// the interior of each face is symmetric, so matching corners of the
+	// patch have the same area
+	var firstArea, lastArea float64 = quadArea(sphere, 0), quadArea(sphere, int32(n*n-1))
+	if math.Abs(firstArea-lastArea) > 1e-9 {
+		t.Errorf("Corner quads differ in area: %f %f", firstArea, lastArea)
+	}
+}
+
+func quadArea(theGrid WingedGrid, faceIndex int32) float64 {
+	normal, _, _ := theGrid.faceNormalAndCenter(faceIndex)
+	return vectorLength(normal) / 2
+}
+
+func TestSubdivideQuadsErrors(t *testing.T) {
+	icosahedron, _ := BaseIcosahedron()
+	if _, err := icosahedron.SubdivideQuads(2); err == nil {
+		t.Error("Expected an error subdividing triangles")
+	}
+	cube, _ := BaseCube()
+	if _, err := cube.SubdivideQuads(0); err == nil {
+		t.Error("Expected an error for a zero patch size")
+	}
+	same, err := cube.SubdivideQuads(1)
+	if err != nil || len(same.Faces) != 6 || len(same.Vertices) != 8 {
+		t.Errorf("Expected a patch size of one to keep the cube, err: %v", err)
+	}
+}
@@ -0,0 +1,165 @@
+package wingedGrid
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// Quasi-uniform triangulated spheres with any number of vertices, for when the
+// 10n²+2 vertices of a subdivided icosahedron don't fit. Points are placed on
+// the unit sphere and joined by SphericalDelaunay, so each grid has count
+// vertices, 2*count-4 triangles and mostly six edges per vertex.
+
+// Sets up a sphere from the Fibonacci lattice: vertex i is at height
+// z = 1 - (2i+1)/count, turned the golden angle further around z than the one
+// before it.
+func FibonacciSphere(count int32) (WingedGrid, error) {
+	err := checkSpherePointCount(count, 4)
+	if err != nil {
+		return WingedGrid{}, err
+	}
+	return SphericalDelaunay(fibonacciPoints(count))
+}
+
+// Sets up a sphere with vertices on rings of constant latitude, placed as the
+// pixel centers of HEALPix (Górski et al. 2005): rings in the polar caps have
+// four more vertices each step from the pole, the rest all the same number,
+// and each vertex stands for the same area. With count = 12N² this gives the
+// HEALPix centers of resolution N, with other counts the number of vertices in
+// each ring is scaled to fit, down to the three rings of resolution 1 for
+// counts below 12. Vertices are in ring order from the north pole, and
+// counter-clockwise around z within each ring.
+func HEALPixSphere(count int32) (WingedGrid, error) {
+	err := checkSpherePointCount(count, 4)
+	if err != nil {
+		return WingedGrid{}, err
+	}
+	return SphericalDelaunay(healpixPoints(count))
+}
+
+// Sets up a sphere from the Fibonacci lattice, moving each vertex in a random
+// direction by up to jitter times half the typical distance between vertices.
+// A jitter of 0 gives FibonacciSphere, 1 the most irregular grid, and the same
+// seed always gives the same grid.
+func JitteredSphere(count int32, jitter float64, seed int64) (WingedGrid, error) {
+	err := checkSpherePointCount(count, 4)
+	if err != nil {
+		return WingedGrid{}, err
+	}
+	if !(jitter >= 0 && jitter <= 1) {
+		return WingedGrid{}, errors.New("Jitter must be between 0 and 1")
+	}
+	var points [][3]float64 = fibonacciPoints(count)
+	if jitter == 0 {
+		return SphericalDelaunay(points)
+	}
+	var random *rand.Rand = rand.New(rand.NewSource(seed))
+	var spacing float64 = math.Sqrt(4 * math.Pi / float64(count))
+	for index, point := range points {
+		// a basis for the plane touching the sphere at the point
+		var east [3]float64 = [3]float64{-point[1], point[0], 0}
+		if vectorLength(east) < 1e-9 {
+			east = [3]float64{1, 0, 0}
+		}
+		east, _ = normalize3VectorWithScale(east)
+		var north [3]float64 = vectorCross(point, east)
+
+		// uniform over a disc
+		var distance float64 = jitter * spacing / 2 * math.Sqrt(random.Float64())
+		var angle float64 = 2 * math.Pi * random.Float64()
+		var moved [3]float64
+		for axis := range moved {
+			moved[axis] = point[axis] + distance*(math.Cos(angle)*east[axis]+math.Sin(angle)*north[axis])
+		}
+		points[index], _ = normalize3VectorWithScale(moved)
+	}
+	return SphericalDelaunay(points)
+}
+
+func checkSpherePointCount(count, minimum int32) error {
+	if count < minimum {
+		return errors.New("Too few vertices for a sphere")
+	}
+	if int64(count)*3 > maxGridElements {
+		return errors.New("Too many elements for int32 indices")
+	}
+	return nil
+}
+
+func fibonacciPoints(count int32) [][3]float64 {
+	var goldenAngle float64 = math.Pi * (3 - math.Sqrt(5))
+	var points [][3]float64 = make([][3]float64, count)
+	for i := range points {
+		var z float64 = 1 - (2*float64(i)+1)/float64(count)
+		var radius float64 = math.Sqrt(1 - z*z)
+		var angle float64 = goldenAngle * float64(i)
+		points[i] = [3]float64{radius * math.Cos(angle), radius * math.Sin(angle), z}
+	}
+	return points
+}
+
+func healpixPoints(count int32) [][3]float64 {
+	var resolution int = int(math.Max(1, math.Round(math.Sqrt(float64(count)/12))))
+	var ringCount int = 4*resolution - 1
+	var heights []float64 = make([]float64, ringCount)
+	var sizes []int = make([]int, ringCount)
+	var shifted []bool = make([]bool, ringCount)
+	var n float64 = float64(resolution)
+	for ring := 1; ring <= ringCount; ring++ {
+		// rings from the nearer pole
+		var fromPole int = ring
+		if ring > 2*resolution {
+			fromPole = 4*resolution - ring
+		}
+		var z float64
+		if fromPole < resolution {
+			z = 1 - float64(fromPole*fromPole)/(3*n*n)
+			sizes[ring-1] = 4 * fromPole
+			shifted[ring-1] = true
+		} else {
+			z = 4.0/3 - 2*float64(fromPole)/(3*n)
+			sizes[ring-1] = 4 * resolution
+			shifted[ring-1] = (fromPole-resolution)%2 == 0
+		}
+		if ring > 2*resolution {
+			z = -z
+		}
+		heights[ring-1] = z
+	}
+
+	// scale the ring sizes to the count, rounding by largest remainder
+	var total int = 12 * resolution * resolution
+	var remainders []float64 = make([]float64, ringCount)
+	var order []int = make([]int, ringCount)
+	var assigned int
+	for ring := range sizes {
+		var scaled float64 = float64(sizes[ring]) * float64(count) / float64(total)
+		sizes[ring] = int(scaled)
+		remainders[ring] = scaled - float64(sizes[ring])
+		assigned += sizes[ring]
+		order[ring] = ring
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return remainders[order[i]] > remainders[order[j]]
+	})
+	for i := 0; assigned < int(count); i++ {
+		sizes[order[i]]++
+		assigned++
+	}
+
+	var points [][3]float64 = make([][3]float64, 0, count)
+	for ring, size := range sizes {
+		var radius float64 = math.Sqrt(1 - heights[ring]*heights[ring])
+		var shift float64
+		if shifted[ring] {
+			shift = 0.5
+		}
+		for j := 0; j < size; j++ {
+			var angle float64 = 2 * math.Pi * (float64(j) + shift) / float64(size)
+			points = append(points, [3]float64{radius * math.Cos(angle), radius * math.Sin(angle), heights[ring]})
+		}
+	}
+	return points
+}
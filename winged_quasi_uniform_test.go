@@ -0,0 +1,110 @@
+package wingedGrid
+
+import (
+	"math"
+	"testing"
+)
+
+func TestQuasiUniformSpheres(t *testing.T) {
+	var generators = map[string]func(count int32) (WingedGrid, error){
+		"fibonacci": FibonacciSphere,
+		"healpix":   HEALPixSphere,
+		"jittered": func(count int32) (WingedGrid, error) {
+			return JitteredSphere(count, 0.8, 7)
+		},
+	}
+	for name, generator := range generators {
+		for _, count := range []int{12, 13, 100, 1001, 4321} {
+			grid, err := generator(int32(count))
+			if err != nil {
+				t.Fatalf("Failed to create %s sphere of %d: %s", name, count, err)
+			}
+			if len(grid.Vertices) != count || len(grid.Faces) != 2*count-4 {
+				t.Errorf("%s %d: got %d vertices and %d faces", name, count, len(grid.Vertices), len(grid.Faces))
+			}
+			if report := grid.Validate(ValidateOptions{Spherical: true}); !report.Valid() {
+				t.Errorf("%s %d invalid: %s", name, count, report.Err())
+			}
+			for index, vertex := range grid.Vertices {
+				if math.Abs(vectorLength(vertex.Coords)-1) > 1e-12 {
+					t.Errorf("%s %d: vertex %d is off the unit sphere", name, count, index)
+				}
+			}
+		}
+	}
+}
+
+func TestFibonacciSphereHeights(t *testing.T) {
+	grid, err := FibonacciSphere(50)
+	if err != nil {
+		t.Fatalf("Failed to create sphere: %s", err)
+	}
+	for index, vertex := range grid.Vertices {
+		if math.Abs(vertex.Coords[2]-(1-float64(2*index+1)/50)) > 1e-12 {
+			t.Errorf("Vertex %d is at height %f", index, vertex.Coords[2])
+		}
+	}
+}
+
+func TestHEALPixSphereRings(t *testing.T) {
+	// resolution 2, rings of 4, 8, 8, 8, 8, 8 and 4
+	grid, err := HEALPixSphere(48)
+	if err != nil {
+		t.Fatalf("Failed to create sphere: %s", err)
+	}
+	var heights []float64 = []float64{11.0 / 12, 2.0 / 3, 1.0 / 3, 0, -1.0 / 3, -2.0 / 3, -11.0 / 12}
+	var sizes []int = []int{4, 8, 8, 8, 8, 8, 4}
+	var index int
+	for ring, size := range sizes {
+		for j := 0; j < size; j++ {
+			if math.Abs(grid.Vertices[index].Coords[2]-heights[ring]) > 1e-12 {
+				t.Errorf("Vertex %d is at height %f, expected %f", index, grid.Vertices[index].Coords[2], heights[ring])
+			}
+			index++
+		}
+	}
+}
+
+func TestJitteredSphere(t *testing.T) {
+	fibonacci, _ := FibonacciSphere(200)
+	still, err := JitteredSphere(200, 0, 3)
+	if err != nil {
+		t.Fatalf("Failed to create sphere: %s", err)
+	}
+	for index := range still.Vertices {
+		if still.Vertices[index].Coords != fibonacci.Vertices[index].Coords {
+			t.Fatalf("Vertex %d moved without jitter", index)
+		}
+	}
+	first, _ := JitteredSphere(200, 1, 3)
+	second, _ := JitteredSphere(200, 1, 3)
+	var spacing float64 = math.Sqrt(4 * math.Pi / 200)
+	for index := range first.Vertices {
+		if first.Vertices[index].Coords != second.Vertices[index].Coords {
+			t.Fatalf("Vertex %d differs for the same seed", index)
+		}
+		if distanceBetween3Points(first.Vertices[index].Coords, fibonacci.Vertices[index].Coords) > spacing/2 {
+			t.Errorf("Vertex %d moved more than half the spacing", index)
+		}
+	}
+
+	if _, err := JitteredSphere(200, 1.5, 3); err == nil {
+		t.Error("Expected an error for jitter above 1")
+	}
+	if _, err := HEALPixSphere(3); err == nil {
+		t.Error("Expected an error for too few vertices")
+	}
+	// fewer than 12 scales down the rings of resolution 1
+	for count := int32(4); count < 12; count++ {
+		grid, err := HEALPixSphere(count)
+		if err != nil {
+			t.Fatalf("Failed to create sphere of %d: %s", count, err)
+		}
+		if len(grid.Vertices) != int(count) {
+			t.Errorf("Expected %d vertices, got %d", count, len(grid.Vertices))
+		}
+		if report := grid.Validate(ValidateOptions{Spherical: true}); !report.Valid() {
+			t.Errorf("Sphere of %d invalid: %s", count, report.Err())
+		}
+	}
+}
@@ -0,0 +1,232 @@
+package wingedGrid
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Adaptive red-green refinement (Bank, Sherman and Weiser 1983) of triangle
+// grids, splitting only chosen faces and the faces needed to keep the grid
+// conforming, with a record of where each new face came from so the
+// refinement can be undone.
+
+// the coarse face of each face of a grid from WingedGrid.RefineFaces
+type Refinement struct {
+	// the coarse face each fine face is part of, in increasing order
+	Parents []int32
+	// the number of coarse vertices, which keep their index in the fine grid
+	CoarseVertexCount int32
+}
+
+// Splits the chosen triangles into four at their edge midpoints, as red
+// faces. Any other face with two or more split edges is also made red, and
+// one with a single split edge becomes two green triangles joined to its
+// midpoint, leaving no vertex in the middle of an edge. New vertices are on
+// the straight edges, as for SubdivideTriangles.
+//
+// The old vertices keep their index, followed by one vertex per split edge in
+// edge order. Each old face (v0, v1, v2) is replaced in order by its children:
+// itself if unsplit; if green, cut from the midpoint m to the opposite corner,
+// the half with the edge leaving v0 starting at v0 and then the other half
+// starting at m; and if red (v0, m0, m2), (v1, m1, m0), (v2, m2, m1),
+// (m0, m1, m2) with mk on the edge from vk, as for SubdivideLoop.
+//
+// Green triangles are thinner than their parent, so refining around one again
+// is better done by coarsening and refining the parents together.
+func (oldGrid WingedGrid) RefineFaces(faces []int32) (WingedGrid, Refinement, error) {
+	faceVertices, err := oldGrid.faceVertexLists()
+	if err != nil {
+		return WingedGrid{}, Refinement{}, err
+	}
+	for faceIndex, vertices := range faceVertices {
+		if len(vertices) != 3 {
+			return WingedGrid{}, Refinement{}, fmt.Errorf("Face %d has %d edges, red-green refinement needs triangles", faceIndex, len(vertices))
+		}
+	}
+
+	// spread red faces until every face has at most one split edge or is red
+	var red []bool = make([]bool, len(oldGrid.Faces))
+	var split []bool = make([]bool, len(oldGrid.Edges))
+	var queue []int32
+	for _, faceIndex := range faces {
+		if !indexInRange(faceIndex, len(oldGrid.Faces)) {
+			return WingedGrid{}, Refinement{}, fmt.Errorf("Face %d out of range", faceIndex)
+		}
+		if !red[faceIndex] {
+			red[faceIndex] = true
+			queue = append(queue, faceIndex)
+		}
+	}
+	for len(queue) > 0 {
+		var faceIndex int32 = queue[len(queue)-1]
+		queue = queue[:len(queue)-1]
+		for _, edgeIndex := range oldGrid.Faces[faceIndex].Edges {
+			if split[edgeIndex] {
+				continue
+			}
+			split[edgeIndex] = true
+			var other int32 = oldGrid.Edges[edgeIndex].FaceA
+			if other == faceIndex {
+				other = oldGrid.Edges[edgeIndex].FaceB
+			}
+			if other == NoFace || red[other] {
+				continue
+			}
+			if oldGrid.splitEdgeCount(other, split) > 1 {
+				red[other] = true
+				queue = append(queue, other)
+			}
+		}
+	}
+
+	var coords [][3]float64 = oldGrid.vertexCoords()
+	var midpoints []int32 = make([]int32, len(oldGrid.Edges))
+	for edgeIndex, edge := range oldGrid.Edges {
+		if split[edgeIndex] {
+			midpoints[edgeIndex] = int32(len(coords))
+			coords = append(coords, oldGrid.edgePoint(edge, edge.FirstVertexA, 0.5))
+		}
+	}
+	if len(coords) > maxGridElements || len(oldGrid.Faces)*4 > maxGridElements {
+		return WingedGrid{}, Refinement{}, errors.New("Too many elements for int32 indices")
+	}
+
+	var newFaces [][]int32
+	var refinement Refinement = Refinement{CoarseVertexCount: int32(len(oldGrid.Vertices))}
+	for faceIndex, vertices := range faceVertices {
+		var edges []int32 = oldGrid.Faces[faceIndex].Edges
+		var count int = len(newFaces)
+		switch {
+		case red[faceIndex]:
+			var middle [3]int32 = [3]int32{midpoints[edges[0]], midpoints[edges[1]], midpoints[edges[2]]}
+			newFaces = append(newFaces,
+				[]int32{vertices[0], middle[0], middle[2]},
+				[]int32{vertices[1], middle[1], middle[0]},
+				[]int32{vertices[2], middle[2], middle[1]},
+				[]int32{middle[0], middle[1], middle[2]})
+		case oldGrid.splitEdgeCount(int32(faceIndex), split) == 1:
+			var k int
+			for !split[edges[k]] {
+				k++
+			}
+			var middle int32 = midpoints[edges[k]]
+			switch k {
+			case 0:
+				newFaces = append(newFaces, []int32{vertices[0], middle, vertices[2]}, []int32{middle, vertices[1], vertices[2]})
+			case 1:
+				newFaces = append(newFaces, []int32{vertices[0], vertices[1], middle}, []int32{middle, vertices[2], vertices[0]})
+			case 2:
+				newFaces = append(newFaces, []int32{vertices[0], vertices[1], middle}, []int32{middle, vertices[1], vertices[2]})
+			}
+		default:
+			newFaces = append(newFaces, append([]int32(nil), vertices...))
+		}
+		for ; count < len(newFaces); count++ {
+			refinement.Parents = append(refinement.Parents, int32(faceIndex))
+		}
+	}
+
+	newGrid, err := NewGridFromPolygons(coords, newFaces)
+	if err != nil {
+		return WingedGrid{}, Refinement{}, err
+	}
+	return newGrid, refinement, nil
+}
+
+func (theGrid WingedGrid) splitEdgeCount(faceIndex int32, split []bool) int {
+	var count int
+	for _, edgeIndex := range theGrid.Faces[faceIndex].Edges {
+		if split[edgeIndex] {
+			count++
+		}
+	}
+	return count
+}
+
+// Returns the fine faces of each coarse face, in order
+func (refinement Refinement) Children() [][]int32 {
+	var children [][]int32
+	for fineIndex, parent := range refinement.Parents {
+		for int(parent) >= len(children) {
+			children = append(children, nil)
+		}
+		children[parent] = append(children[parent], int32(fineIndex))
+	}
+	return children
+}
+
+// Undoes the refinement of a grid from WingedGrid.RefineFaces, merging the
+// children of each coarse face and dropping the added vertices. The coarse
+// vertices take their coordinates from the fine grid, so any movement since
+// refining is kept. Vertices and faces have their coarse indices, edges are
+// numbered afresh as by NewGridFromPolygons.
+func (refinement Refinement) Coarsen(fineGrid WingedGrid) (WingedGrid, error) {
+	if len(refinement.Parents) != len(fineGrid.Faces) {
+		return WingedGrid{}, fmt.Errorf("Refinement has %d faces, grid has %d", len(refinement.Parents), len(fineGrid.Faces))
+	}
+	if refinement.CoarseVertexCount < 0 || int(refinement.CoarseVertexCount) > len(fineGrid.Vertices) {
+		return WingedGrid{}, fmt.Errorf("Refinement has %d coarse vertices, grid has %d", refinement.CoarseVertexCount, len(fineGrid.Vertices))
+	}
+	for fineIndex := 1; fineIndex < len(refinement.Parents); fineIndex++ {
+		if refinement.Parents[fineIndex] < refinement.Parents[fineIndex-1] {
+			return WingedGrid{}, fmt.Errorf("Face %d has parent %d after %d", fineIndex, refinement.Parents[fineIndex], refinement.Parents[fineIndex-1])
+		}
+	}
+	if len(refinement.Parents) > 0 && refinement.Parents[0] < 0 {
+		return WingedGrid{}, fmt.Errorf("Face 0 has parent %d", refinement.Parents[0])
+	}
+	fineVertices, err := fineGrid.faceVertexLists()
+	if err != nil {
+		return WingedGrid{}, err
+	}
+
+	var faces [][]int32
+	for parent, children := range refinement.Children() {
+		var corners []int32
+		switch len(children) {
+		case 1:
+			corners = fineVertices[children[0]]
+		case 2:
+			corners = mergeGreenTriangles(fineVertices[children[0]], fineVertices[children[1]])
+			if corners == nil {
+				return WingedGrid{}, fmt.Errorf("Face %d has children that aren't triangles sharing an edge", parent)
+			}
+		case 4:
+			corners = make([]int32, 0, 3)
+			for _, child := range children[:3] {
+				corners = append(corners, fineVertices[child][0])
+			}
+		default:
+			return WingedGrid{}, fmt.Errorf("Face %d has %d children", parent, len(children))
+		}
+		var face []int32
+		for _, vertexIndex := range corners {
+			if vertexIndex < refinement.CoarseVertexCount {
+				face = append(face, vertexIndex)
+			}
+		}
+		if len(face) != 3 {
+			return WingedGrid{}, fmt.Errorf("Face %d has %d coarse corners", parent, len(face))
+		}
+		faces = append(faces, face)
+	}
+	return NewGridFromPolygons(fineGrid.vertexCoords()[:refinement.CoarseVertexCount], faces)
+}
+
+// Returns the quad of two triangles sharing an edge, starting at the first
+// corner of the first, or nil if they aren't
+func mergeGreenTriangles(first, second []int32) []int32 {
+	if len(first) != 3 || len(second) != 3 {
+		return nil
+	}
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			if first[i] == second[(j+1)%3] && first[(i+1)%3] == second[j] {
+				var quad []int32 = append([]int32(nil), first[:i+1]...)
+				quad = append(quad, second[(j+2)%3])
+				return append(quad, first[i+1:]...)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package wingedGrid
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRefineFacesSingle(t *testing.T) {
+	base, _ := BaseIcosahedron()
+	grid, refinement, err := base.RefineFaces([]int32{0})
+	if err != nil {
+		t.Fatalf("Failed to refine: %s", err)
+	}
+	if report := grid.Validate(ValidateOptions{Spherical: true}); !report.Valid() {
+		t.Fatalf("Refined grid invalid: %s", report.Err())
+	}
+	// one red face and its three neighbors green
+	if len(grid.Vertices) != 15 || len(grid.Faces) != 20-4+4+3*2 {
+		t.Errorf("Unexpected counts %d vertices, %d faces", len(grid.Vertices), len(grid.Faces))
+	}
+	var children [][]int32 = refinement.Children()
+	if len(children) != 20 || len(children[0]) != 4 {
+		t.Fatalf("Unexpected children %v", children)
+	}
+	var green int
+	for _, list := range children {
+		if len(list) == 2 {
+			green++
+		}
+	}
+	if green != 3 {
+		t.Errorf("Expected 3 green faces, got %d", green)
+	}
+	for fineIndex, parent := range refinement.Parents {
+		var found bool
+		for _, child := range children[parent] {
+			found = found || child == int32(fineIndex)
+		}
+		if !found {
+			t.Errorf("Face %d isn't a child of its parent %d", fineIndex, parent)
+		}
+	}
+}
+
+func TestRefineFacesClosure(t *testing.T) {
+	base, _ := BaseIcosahedron()
+	// two neighbors of face 0, which then has two split edges
+	var neighbors []int32
+	for _, edgeIndex := range base.Faces[0].Edges[:2] {
+		var edge WingedEdge = base.Edges[edgeIndex]
+		if edge.FaceA == 0 {
+			neighbors = append(neighbors, edge.FaceB)
+		} else {
+			neighbors = append(neighbors, edge.FaceA)
+		}
+	}
+	grid, refinement, err := base.RefineFaces(neighbors)
+	if err != nil {
+		t.Fatalf("Failed to refine: %s", err)
+	}
+	if report := grid.Validate(ValidateOptions{Spherical: true}); !report.Valid() {
+		t.Fatalf("Refined grid invalid: %s", report.Err())
+	}
+	var children [][]int32 = refinement.Children()
+	if len(children[0]) != 4 {
+		t.Errorf("Expected face 0 to be red, got %d children", len(children[0]))
+	}
+	var red, green int
+	for _, list := range children {
+		switch len(list) {
+		case 4:
+			red++
+		case 2:
+			green++
+		}
+	}
+	// the three red faces are a strip with five faces along its sides
+	if red != 3 || green != 5 {
+		t.Errorf("Expected 3 red and 5 green faces, got %d and %d", red, green)
+	}
+}
+
+func TestRefineFacesCoarsen(t *testing.T) {
+	base, err := PlanarTriangleMap(8, 6, NoWrap)
+	if err != nil {
+		t.Fatalf("Failed to create map: %s", err)
+	}
+	grid, refinement, err := base.RefineFaces([]int32{10, 11, 40, 0})
+	if err != nil {
+		t.Fatalf("Failed to refine: %s", err)
+	}
+	if report := grid.Validate(ValidateOptions{}); !report.Valid() {
+		t.Fatalf("Refined grid invalid: %s", report.Err())
+	}
+	// refine again, then undo both levels
+	finer, finerRefinement, err := grid.RefineFaces([]int32{0, 1, 2})
+	if err != nil {
+		t.Fatalf("Failed to refine again: %s", err)
+	}
+	coarse, err := finerRefinement.Coarsen(finer)
+	if err != nil {
+		t.Fatalf("Failed to coarsen: %s", err)
+	}
+	coarser, err := refinement.Coarsen(coarse)
+	if err != nil {
+		t.Fatalf("Failed to coarsen: %s", err)
+	}
+	if !reflect.DeepEqual(mustFaceVertexLists(t, coarser), mustFaceVertexLists(t, base)) {
+		t.Errorf("Coarsened faces don't match the original")
+	}
+	for index, vertex := range base.Vertices {
+		if coarser.Vertices[index].Coords != vertex.Coords {
+			t.Errorf("Vertex %d moved", index)
+		}
+	}
+	if len(coarser.Vertices) != len(base.Vertices) || len(coarser.Edges) != len(base.Edges) {
+		t.Errorf("Coarsened grid has %d vertices and %d edges", len(coarser.Vertices), len(coarser.Edges))
+	}
+
+	if _, err := refinement.Coarsen(base); err == nil {
+		t.Error("Expected an error coarsening the wrong grid")
+	}
+}
+
+func TestRefineFacesInvalid(t *testing.T) {
+	base, _ := BaseIcosahedron()
+	if _, _, err := base.RefineFaces([]int32{20}); err == nil {
+		t.Error("Expected an error for a face out of range")
+	}
+	cube, _ := BaseCube()
+	if _, _, err := cube.RefineFaces([]int32{0}); err == nil {
+		t.Error("Expected an error for quad faces")
+	}
+	// nothing chosen leaves the grid as it was
+	grid, refinement, err := base.RefineFaces(nil)
+	if err != nil {
+		t.Fatalf("Failed to refine nothing: %s", err)
+	}
+	if len(grid.Faces) != 20 || len(refinement.Parents) != 20 {
+		t.Errorf("Expected the grid unchanged, got %d faces", len(grid.Faces))
+	}
+}
@@ -0,0 +1,92 @@
+package wingedGrid
+
+import (
+	"errors"
+)
+
+// Where the vertices and faces of a grid from SubdivideTriangles came from,
+// for moving data between the old grid and the subdivided one.
+//
+// SubdivideTriangles keeps the old vertices at their index, followed by the n
+// vertices on each old edge from FirstVertexA to FirstVertexB, edge by edge,
+// and then the n(n-1)/2 vertices inside each old face, face by face. Each old
+// face is replaced by (n+1)^2 faces, face by face.
+
+// the layout of SubdivideTriangles for one grid and number of subdivisions
+type SubdivisionMap struct {
+	// element counts of the old grid
+	vertexCount, edgeCount, faceCount int32
+	edgeSubdivisions                  int32
+}
+
+// Returns the layout SubdivideTriangles gives the grid for the number of
+// subdivisions
+func (oldGrid WingedGrid) TriangleSubdivisionMap(edgeSubdivisions int32) (SubdivisionMap, error) {
+	if edgeSubdivisions < 1 {
+		return SubdivisionMap{}, errors.New("Invalid number of subdivisions")
+	}
+	var n, faceCount int64 = int64(edgeSubdivisions), int64(len(oldGrid.Faces))
+	if (n+1)*(n+1) > maxGridElements {
+		return SubdivisionMap{}, errors.New("Too many elements for int32 indices")
+	}
+	// with n below 2^16 none of the counts overflow int64
+	var edgeCount int64 = int64(len(oldGrid.Edges))*(n+1) + faceCount*3*n*(n+1)/2
+	var vertexCount int64 = int64(len(oldGrid.Vertices)) + int64(len(oldGrid.Edges))*n + faceCount*n*(n-1)/2
+	if faceCount*(n+1)*(n+1) > maxGridElements || edgeCount > maxGridElements || vertexCount > maxGridElements {
+		return SubdivisionMap{}, errors.New("Too many elements for int32 indices")
+	}
+	return SubdivisionMap{
+		vertexCount:      int32(len(oldGrid.Vertices)),
+		edgeCount:        int32(len(oldGrid.Edges)),
+		faceCount:        int32(len(oldGrid.Faces)),
+		edgeSubdivisions: edgeSubdivisions,
+	}, nil
+}
+
+func (theMap SubdivisionMap) childrenPerFace() int32 {
+	return (theMap.edgeSubdivisions + 1) * (theMap.edgeSubdivisions + 1)
+}
+
+func (theMap SubdivisionMap) verticesPerFace() int32 {
+	return theMap.edgeSubdivisions * (theMap.edgeSubdivisions - 1) / 2
+}
+
+// Returns the old face a new face is part of, or NoFace if out of range
+func (theMap SubdivisionMap) ParentFace(newFace int32) int32 {
+	if newFace < 0 || newFace >= theMap.faceCount*theMap.childrenPerFace() {
+		return NoFace
+	}
+	return newFace / theMap.childrenPerFace()
+}
+
+// Returns the new faces an old face was split into, or nil if out of range
+func (theMap SubdivisionMap) ChildFaces(oldFace int32) []int32 {
+	if !indexInRange(oldFace, int(theMap.faceCount)) {
+		return nil
+	}
+	var children []int32 = make([]int32, theMap.childrenPerFace())
+	for index := range children {
+		children[index] = oldFace*theMap.childrenPerFace() + int32(index)
+	}
+	return children
+}
+
+// Returns the old edge a new vertex is on, or NoEdge if it is an old vertex,
+// inside an old face or out of range
+func (theMap SubdivisionMap) OriginEdge(newVertex int32) int32 {
+	var offset int32 = newVertex - theMap.vertexCount
+	if offset < 0 || offset >= theMap.edgeCount*theMap.edgeSubdivisions {
+		return NoEdge
+	}
+	return offset / theMap.edgeSubdivisions
+}
+
+// Returns the old face a new vertex is inside, or NoFace if it is an old
+// vertex, on an old edge or out of range
+func (theMap SubdivisionMap) OriginFace(newVertex int32) int32 {
+	var offset int32 = newVertex - theMap.vertexCount - theMap.edgeCount*theMap.edgeSubdivisions
+	if theMap.verticesPerFace() == 0 || offset < 0 || offset >= theMap.faceCount*theMap.verticesPerFace() {
+		return NoFace
+	}
+	return offset / theMap.verticesPerFace()
+}
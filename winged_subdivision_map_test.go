@@ -0,0 +1,105 @@
+package wingedGrid
+
+import (
+	"testing"
+)
+
+// checks every new vertex of each new face belongs to its parent face
+func checkSubdivisionMap(t *testing.T, base WingedGrid, edgeSubdivisions int32) {
+	grid, err := base.SubdivideTriangles(edgeSubdivisions)
+	if err != nil {
+		t.Fatalf("Failed to subdivide: %s", err)
+	}
+	subdivisionMap, err := base.TriangleSubdivisionMap(edgeSubdivisions)
+	if err != nil {
+		t.Fatalf("Failed to get the map: %s", err)
+	}
+	var baseVertices [][]int32 = mustFaceVertexLists(t, base)
+	var newVertices [][]int32 = mustFaceVertexLists(t, grid)
+	for newFace, vertices := range newVertices {
+		var parent int32 = subdivisionMap.ParentFace(int32(newFace))
+		if parent == NoFace {
+			t.Fatalf("Face %d has no parent", newFace)
+		}
+		for _, vertexIndex := range vertices {
+			var edge, face int32 = subdivisionMap.OriginEdge(vertexIndex), subdivisionMap.OriginFace(vertexIndex)
+			var onParent bool
+			switch {
+			case vertexIndex < int32(len(base.Vertices)):
+				onParent = int32IndexInSlice(vertexIndex, baseVertices[parent]) >= 0
+				onParent = onParent && edge == NoEdge && face == NoFace
+			case edge != NoEdge:
+				onParent = int32IndexInSlice(edge, base.Faces[parent].Edges) >= 0 && face == NoFace
+			default:
+				onParent = face == parent
+			}
+			if !onParent {
+				t.Fatalf("Vertex %d of face %d isn't on its parent %d", vertexIndex, newFace, parent)
+			}
+		}
+	}
+	var childCount int
+	for oldFace := range base.Faces {
+		for _, child := range subdivisionMap.ChildFaces(int32(oldFace)) {
+			if subdivisionMap.ParentFace(child) != int32(oldFace) {
+				t.Errorf("Child %d of face %d has parent %d", child, oldFace, subdivisionMap.ParentFace(child))
+			}
+			childCount++
+		}
+	}
+	if childCount != len(grid.Faces) {
+		t.Errorf("Expected %d children, got %d", len(grid.Faces), childCount)
+	}
+	// edge vertices lie between the ends of their edge
+	for vertexIndex := range grid.Vertices {
+		var edgeIndex int32 = subdivisionMap.OriginEdge(int32(vertexIndex))
+		if edgeIndex == NoEdge {
+			continue
+		}
+		var edge WingedEdge = base.Edges[edgeIndex]
+		var first, second [3]float64 = base.Vertices[edge.FirstVertexA].Coords, base.Vertices[edge.FirstVertexB].Coords
+		var point [3]float64 = grid.Vertices[vertexIndex].Coords
+		if distanceBetween3Points(first, point)+distanceBetween3Points(point, second)-distanceBetween3Points(first, second) > 1e-9 {
+			t.Errorf("Vertex %d isn't on edge %d", vertexIndex, edgeIndex)
+		}
+	}
+}
+
+func TestTriangleSubdivisionMap(t *testing.T) {
+	icosahedron, _ := BaseIcosahedron()
+	for _, n := range []int32{1, 2, 4} {
+		checkSubdivisionMap(t, icosahedron, n)
+	}
+	patch, err := PlanarTriangleHexagon(2)
+	if err != nil {
+		t.Fatalf("Failed to create patch: %s", err)
+	}
+	checkSubdivisionMap(t, patch, 3)
+}
+
+func TestTriangleSubdivisionMapRanges(t *testing.T) {
+	icosahedron, _ := BaseIcosahedron()
+	if _, err := icosahedron.TriangleSubdivisionMap(0); err == nil {
+		t.Error("Expected an error for no subdivisions")
+	}
+	if _, err := icosahedron.TriangleSubdivisionMap(50000); err == nil {
+		t.Error("Expected an error for too many faces")
+	}
+	if _, err := icosahedron.TriangleSubdivisionMap(1<<31 - 1); err == nil {
+		t.Error("Expected an error for too many children per face")
+	}
+	subdivisionMap, _ := icosahedron.TriangleSubdivisionMap(2)
+	// 12 old vertices, 60 on edges and 20 inside faces
+	if subdivisionMap.OriginEdge(11) != NoEdge || subdivisionMap.OriginEdge(12) != 0 || subdivisionMap.OriginEdge(71) != 29 || subdivisionMap.OriginEdge(72) != NoEdge {
+		t.Errorf("Unexpected edge origins")
+	}
+	if subdivisionMap.OriginFace(71) != NoFace || subdivisionMap.OriginFace(72) != 0 || subdivisionMap.OriginFace(91) != 19 || subdivisionMap.OriginFace(92) != NoFace {
+		t.Errorf("Unexpected face origins")
+	}
+	if subdivisionMap.ParentFace(-1) != NoFace || subdivisionMap.ParentFace(180) != NoFace || subdivisionMap.ParentFace(179) != 19 {
+		t.Errorf("Unexpected parents")
+	}
+	if subdivisionMap.ChildFaces(20) != nil || len(subdivisionMap.ChildFaces(0)) != 9 {
+		t.Errorf("Unexpected children")
+	}
+}
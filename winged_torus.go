@@ -0,0 +1,48 @@
+package wingedGrid
+
+import (
+	"errors"
+	"math"
+)
+
+// Sets up a triangulated torus around the z axis, with majorSegments steps
+// around the axis and minorSegments steps around the tube. The vertex at step
+// i around the axis and j around the tube is at index i*minorSegments+j,
+// placed at angles u = 2πi/majorSegments and v = 2πj/minorSegments on
+//
+//	((R + r cos v) cos u, (R + r cos v) sin u, r sin v)
+//
+// Each step of the parameter grid is split into two triangles along the same
+// diagonal, faces 2*(i*minorSegments+j) and the one after it, so neighbors
+// wrap around in both directions with every vertex having six edges.
+func BaseTorus(majorRadius, minorRadius float64, majorSegments, minorSegments int32) (WingedGrid, error) {
+	if !(minorRadius > 0) || !(majorRadius > minorRadius) {
+		return WingedGrid{}, errors.New("Torus radii must satisfy majorRadius > minorRadius > 0")
+	}
+	// fewer segments would join some pair of vertices with two edges
+	if majorSegments < 3 || minorSegments < 3 {
+		return WingedGrid{}, errors.New("Torus needs at least three segments each way")
+	}
+	if int64(majorSegments)*int64(minorSegments)*3 > maxGridElements {
+		return WingedGrid{}, errors.New("Too many elements for int32 indices")
+	}
+	var index = func(i, j int32) int32 {
+		return (i%majorSegments)*minorSegments + j%minorSegments
+	}
+	var coords [][3]float64 = make([][3]float64, majorSegments*minorSegments)
+	var faces [][]int32 = make([][]int32, 0, 2*majorSegments*minorSegments)
+	var i, j int32
+	for i = 0; i < majorSegments; i++ {
+		var u float64 = 2 * math.Pi * float64(i) / float64(majorSegments)
+		for j = 0; j < minorSegments; j++ {
+			var v float64 = 2 * math.Pi * float64(j) / float64(minorSegments)
+			var ring float64 = majorRadius + minorRadius*math.Cos(v)
+			coords[index(i, j)] = [3]float64{ring * math.Cos(u), ring * math.Sin(u), minorRadius * math.Sin(v)}
+			// counter-clockwise seen from outside, as u then v increase
+			faces = append(faces,
+				[]int32{index(i, j), index(i+1, j), index(i+1, j+1)},
+				[]int32{index(i, j), index(i+1, j+1), index(i, j+1)})
+		}
+	}
+	return NewGridFromPolygons(coords, faces)
+}
@@ -0,0 +1,83 @@
+package wingedGrid
+
+import (
+	"math"
+	"testing"
+)
+
+func TestBaseTorus(t *testing.T) {
+	torus, err := BaseTorus(3, 1, 8, 5)
+	if err != nil {
+		t.Fatalf("Failed to create torus: %s", err)
+	}
+	if len(torus.Vertices) != 40 || len(torus.Faces) != 80 || len(torus.Edges) != 120 {
+		t.Fatalf("Unexpected counts %d %d %d", len(torus.Vertices), len(torus.Edges), len(torus.Faces))
+	}
+	if report := torus.Validate(ValidateOptions{}); !report.Valid() {
+		t.Fatalf("Torus is invalid: %s", report.Err())
+	}
+	for index, vertex := range torus.Vertices {
+		if len(vertex.Edges) != 6 {
+			t.Errorf("Vertex %d has %d edges, expected 6", index, len(vertex.Edges))
+		}
+		// distance from the tube's center circle is the minor radius
+		var ring float64 = math.Hypot(vertex.Coords[0], vertex.Coords[1]) - 3
+		if math.Abs(math.Hypot(ring, vertex.Coords[2])-1) > 1e-12 {
+			t.Errorf("Vertex %d is off the torus", index)
+		}
+	}
+	// faces point away from the tube's center circle
+	for index := range torus.Faces {
+		normal, center, ok := torus.faceNormalAndCenter(int32(index))
+		if !ok {
+			t.Fatalf("Face %d has no normal", index)
+		}
+		var scale float64 = 3 / math.Hypot(center[0], center[1])
+		var outward [3]float64 = [3]float64{center[0] - center[0]*scale, center[1] - center[1]*scale, center[2]}
+		if normal[0]*outward[0]+normal[1]*outward[1]+normal[2]*outward[2] <= 0 {
+			t.Errorf("Face %d points into the tube", index)
+		}
+	}
+}
+
+func TestBaseTorusInvalid(t *testing.T) {
+	var cases = [][4]float64{{1, 1, 8, 8}, {3, 0, 8, 8}, {3, 1, 2, 8}, {3, 1, 8, 2}}
+	for _, c := range cases {
+		if _, err := BaseTorus(c[0], c[1], int32(c[2]), int32(c[3])); err == nil {
+			t.Errorf("Expected an error for %v", c)
+		}
+	}
+}
+
+func TestSubdivideTrianglesTorus(t *testing.T) {
+	torus, err := BaseTorus(3, 1, 6, 4)
+	if err != nil {
+		t.Fatalf("Failed to create torus: %s", err)
+	}
+	for _, n := range []int32{1, 2, 3} {
+		subdivided, err := torus.SubdivideTriangles(n)
+		if err != nil {
+			t.Fatalf("Failed to subdivide %d: %s", n, err)
+		}
+		var faces int = len(torus.Faces) * int((n+1)*(n+1))
+		// Euler characteristic 0
+		if len(subdivided.Faces) != faces || len(subdivided.Edges) != 3*faces/2 || len(subdivided.Vertices) != faces/2 {
+			t.Errorf("Subdivision %d has %d faces, %d edges and %d vertices", n, len(subdivided.Faces), len(subdivided.Edges), len(subdivided.Vertices))
+		}
+		if report := subdivided.Validate(ValidateOptions{}); !report.Valid() {
+			t.Errorf("Subdivision %d is invalid: %s", n, report.Err())
+		}
+		for index, vertex := range subdivided.Vertices {
+			if len(vertex.Edges) != 6 {
+				t.Errorf("Subdivision %d vertex %d has %d edges, expected 6", n, index, len(vertex.Edges))
+			}
+		}
+	}
+	geodesic, err := torus.SubdivideGeodesic(2, 1)
+	if err != nil {
+		t.Fatalf("Failed to subdivide geodesic: %s", err)
+	}
+	if report := geodesic.Validate(ValidateOptions{}); !report.Valid() || len(geodesic.Vertices) != len(geodesic.Faces)/2 {
+		t.Errorf("Geodesic torus is invalid: %s", report.Err())
+	}
+}
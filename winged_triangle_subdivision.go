@@ -6,7 +6,9 @@ import (
 	"math"
 )
 
-// assuming triangular tiling of a surface homeomorphic to S2
+// assuming triangular tiling of an orientable surface, of any genus and
+// possibly with a boundary. Pieces of boundary edges stay on the boundary.
+// TriangleSubdivisionMap gives where each new vertex and face came from.
 func (oldGrid WingedGrid) SubdivideTriangles(edgeSubdivisions int32) (WingedGrid, error) {
 	var err error
 	var dividedGrid WingedGrid
@@ -20,12 +22,15 @@ func (oldGrid WingedGrid) SubdivideTriangles(edgeSubdivisions int32) (WingedGrid
 	//  to the base face count multiplied by (1/2(n+2)(n+1) + 1/2(n+1)(n))
 	faceCount = int32(len(oldGrid.Faces)) * ((edgeSubdivisions+2)*(edgeSubdivisions+1)/2 + (edgeSubdivisions+1)*edgeSubdivisions/2)
 	dividedGrid.Faces = make([]WingedFace, faceCount)
-	// since each face 'owns' 1/2 of three edges, there are 1.5 times as
-	//  many edges as faces
-	dividedGrid.Edges = make([]WingedEdge, 3*faceCount/2)
-	// Euler-somebody or other gives us the vertex count of
-	//  1/2*(face count) + 2
-	dividedGrid.Vertices = make([]WingedVertex, faceCount/2+2)
+	// each old edge is split in n+1, and each old face gets 3/2n(n+1)
+	//  edges inside it, 1.5 times as many edges as faces on a closed surface
+	var edgeCount int32 = int32(len(oldGrid.Edges))*(edgeSubdivisions+1) + int32(len(oldGrid.Faces))*3*edgeSubdivisions*(edgeSubdivisions+1)/2
+	dividedGrid.Edges = make([]WingedEdge, edgeCount)
+	// subdividing keeps the Euler characteristic V - E + F (2 for S2, 0 for
+	//  a torus, 1 for a disc), which gives us the vertex count
+	var eulerCharacteristic int32 = int32(len(oldGrid.Vertices)) - int32(len(oldGrid.Edges)) + int32(len(oldGrid.Faces))
+	var vertexCount int32 = eulerCharacteristic + edgeCount - faceCount
+	dividedGrid.Vertices = make([]WingedVertex, vertexCount)
 
 	// Invalidate all values
 	var i int32
@@ -35,7 +40,7 @@ func (oldGrid WingedGrid) SubdivideTriangles(edgeSubdivisions int32) (WingedGrid
 		dividedGrid.Faces[i].Edges[1] = -1
 		dividedGrid.Faces[i].Edges[2] = -1
 	}
-	for i = 0; i < 3*faceCount/2; i++ {
+	for i = 0; i < edgeCount; i++ {
 		dividedGrid.Edges[i].FaceA = -1
 		dividedGrid.Edges[i].FaceB = -1
 		dividedGrid.Edges[i].FirstVertexA = -1
@@ -46,7 +51,7 @@ func (oldGrid WingedGrid) SubdivideTriangles(edgeSubdivisions int32) (WingedGrid
 		dividedGrid.Edges[i].PrevB = -1
 	}
 	// verticies corrisponding with old ones will have the same number of
-	//  associated edges to preserve the Euler characteristic ( =2 for S2)
+	//  associated edges to preserve the Euler characteristic
 	for i = 0; i < int32(len(oldGrid.Vertices)); i++ {
 		dividedGrid.Vertices[i].Edges = make([]int32, len(oldGrid.Vertices[i].Edges))
 		for j := 0; j < len(oldGrid.Vertices[i].Edges); j++ {
@@ -59,7 +64,7 @@ func (oldGrid WingedGrid) SubdivideTriangles(edgeSubdivisions int32) (WingedGrid
 	}
 	// the way we divide the faces creates six accociated edges for the remaining
 	//  vertecies
-	for i = int32(len(oldGrid.Vertices)); i < faceCount/2+2; i++ {
+	for i = int32(len(oldGrid.Vertices)); i < vertexCount; i++ {
 		dividedGrid.Vertices[i].Edges = make([]int32, 6)
 		dividedGrid.Vertices[i].Edges[0] = -1
 		dividedGrid.Vertices[i].Edges[1] = -1
@@ -562,40 +567,46 @@ func (grid WingedGrid) NormalizeVerticesToDistanceFromOrigin(wantedLength float6
 	}
 }
 
+// Sets the edges of every vertex whose first edge is -1 by walking around it,
+// reusing the vertex's edge array where it is large enough
 func (grid WingedGrid) setEdgesForVerticesIfInvalid() {
 	// loop through edges so we only have to touch each one once
 	for index, edge := range grid.Edges {
-		var nextEdgeIndex int32 = -1
-		var nextEdge WingedEdge
-		var theVertexIndex int32 = edge.FirstVertexA
-		var theVertex WingedVertex = grid.Vertices[theVertexIndex]
-		if theVertex.Edges[0] == -1 {
-			nextEdgeIndex, _ = edge.NextEdgeForVertex(theVertexIndex)
-			nextEdge = grid.Edges[nextEdgeIndex]
-			theVertex.Edges[0] = int32(index)
-			var i int = 1
-			for int32(index) != nextEdgeIndex {
-				theVertex.Edges[i] = nextEdgeIndex
-				nextEdgeIndex, _ = nextEdge.NextEdgeForVertex(theVertexIndex)
-				nextEdge = grid.Edges[nextEdgeIndex]
-				i = i + 1
+		for _, theVertexIndex := range [2]int32{edge.FirstVertexA, edge.FirstVertexB} {
+			var theVertex *WingedVertex = &grid.Vertices[theVertexIndex]
+			if len(theVertex.Edges) == 0 || theVertex.Edges[0] == -1 {
+				theVertex.Edges = grid.edgesAroundVertex(theVertexIndex, int32(index), theVertex.Edges[:0])
 			}
 		}
-		theVertexIndex = edge.FirstVertexB
-		theVertex = grid.Vertices[theVertexIndex]
-		if theVertex.Edges[0] == -1 {
-			nextEdgeIndex, _ = edge.NextEdgeForVertex(theVertexIndex)
-			nextEdge = grid.Edges[nextEdgeIndex]
-			theVertex.Edges[0] = int32(index)
-			var i int = 1
-			for int32(index) != nextEdgeIndex {
-				theVertex.Edges[i] = nextEdgeIndex
-				nextEdgeIndex, _ = nextEdge.NextEdgeForVertex(theVertexIndex)
-				nextEdge = grid.Edges[nextEdgeIndex]
-				i = i + 1
-			}
+	}
+}
+
+// Appends the edges around the vertex in clockwise order, starting from the
+// given edge, or for an open fan on the boundary from its first edge. Stops
+// after visiting every edge once if the edges don't lead back.
+func (grid WingedGrid) edgesAroundVertex(vertexIndex, startEdge int32, edges []int32) []int32 {
+	var first int32 = startEdge
+	for steps := 0; steps < len(grid.Edges); steps++ {
+		prevEdgeIndex, err := grid.Edges[first].PrevEdgeForVertex(vertexIndex)
+		if err != nil || prevEdgeIndex == startEdge {
+			first = startEdge
+			break
+		}
+		if prevEdgeIndex == NoEdge {
+			break
+		}
+		first = prevEdgeIndex
+	}
+	var nextEdgeIndex int32 = first
+	for steps := 0; steps < len(grid.Edges); steps++ {
+		edges = append(edges, nextEdgeIndex)
+		var err error
+		nextEdgeIndex, err = grid.Edges[nextEdgeIndex].NextEdgeForVertex(vertexIndex)
+		if err != nil || nextEdgeIndex == NoEdge || nextEdgeIndex == first {
+			break
 		}
 	}
+	return edges
 }
 
 /******************* Helper Functions ***********************/
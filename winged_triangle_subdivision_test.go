@@ -321,3 +321,47 @@ func _TestIcosahedronSubdivisionFaceOrientation(t *testing.T) {
 		}
 	}
 }
+
+func TestSubdivisionOfOpenGrid(t *testing.T) {
+	base, _ := BaseIcosahedron()
+	patch := northernPatch(t, base)
+	var boundaryEdges int
+	for _, edge := range patch.Edges {
+		if edge.IsBoundary() {
+			boundaryEdges++
+		}
+	}
+	var n int = 3
+	subdivided, err := patch.SubdivideTriangles(int32(n))
+	if err != nil {
+		t.Fatalf("Failed to subdivide patch: %s", err)
+	}
+	report := subdivided.Validate(ValidateOptions{Spherical: true})
+	if !report.Valid() {
+		t.Fatalf("Subdivided patch invalid: %s", report.Err())
+	}
+	if len(subdivided.Faces) != len(patch.Faces)*(n+1)*(n+1) {
+		t.Errorf("Expected %d faces, got %d", len(patch.Faces)*(n+1)*(n+1), len(subdivided.Faces))
+	}
+	euler := len(subdivided.Vertices) - len(subdivided.Edges) + len(subdivided.Faces)
+	if euler != 1 {
+		t.Errorf("Expected Euler characteristic 1, got %d", euler)
+	}
+
+	var newBoundaryEdges int
+	for _, edge := range subdivided.Edges {
+		if edge.IsBoundary() {
+			newBoundaryEdges++
+		}
+	}
+	if newBoundaryEdges != boundaryEdges*(n+1) {
+		t.Errorf("Expected %d boundary edges, got %d", boundaryEdges*(n+1), newBoundaryEdges)
+	}
+	// new vertices along the boundary have two faces and four edges
+	for index := len(patch.Vertices); index < len(subdivided.Vertices); index++ {
+		var edges []int32 = subdivided.Vertices[index].Edges
+		if subdivided.Edges[edges[0]].IsBoundary() && len(edges) != 4 {
+			t.Errorf("Boundary vertex %d has %d edges, expected 4", index, len(edges))
+		}
+	}
+}
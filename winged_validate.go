@@ -0,0 +1,449 @@
+package wingedGrid
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Contains validation of the invariants a WingedGrid is expected to hold.
+// Validation never indexes out of range, so it is safe to run on grids
+// received from an untrusted source before any traversal is attempted.
+
+// the kind of element a violation was found on
+type GridElement int
+
+const (
+	ElementFace GridElement = iota
+	ElementEdge
+	ElementVertex
+)
+
+func (element GridElement) String() string {
+	switch element {
+	case ElementFace:
+		return "face"
+	case ElementEdge:
+		return "edge"
+	case ElementVertex:
+		return "vertex"
+	}
+	return "unknown"
+}
+
+// names the invariant a violation breaks
+type ValidationRule string
+
+const (
+	// an index stored in an element is outside of its array
+	RuleIndexRange ValidationRule = "index-range"
+	// a face has fewer than three edges
+	RuleFaceTooFewEdges ValidationRule = "face-too-few-edges"
+	// a face lists the same edge more than once
+	RuleFaceDuplicateEdge ValidationRule = "face-duplicate-edge"
+	// a face lists an edge that does not reference the face
+	RuleFaceEdgeNotLinked ValidationRule = "face-edge-not-linked"
+	// the face edge order doesn't match the order given by the edges' next
+	RuleFaceEdgeOrder ValidationRule = "face-edge-order"
+	// an edge has the same vertex or the same face on both sides
+	RuleEdgeDegenerate ValidationRule = "edge-degenerate"
+	// an edge's vertices are not ordered clockwise for its faces
+	RuleEdgeOrientation ValidationRule = "edge-orientation"
+	// an edge's prev and next don't point back at it
+	RuleEdgePrevNext ValidationRule = "edge-prev-next"
+	// a boundary edge is missing FaceA rather than FaceB, or has a prev or
+	// next edge for its missing face
+	RuleEdgeBoundary ValidationRule = "edge-boundary"
+	// a vertex has no edges
+	RuleVertexNoEdges ValidationRule = "vertex-no-edges"
+	// a vertex lists an edge that does not reference the vertex
+	RuleVertexEdgeNotLinked ValidationRule = "vertex-edge-not-linked"
+	// the vertex edge order doesn't match the clockwise order of the edges
+	RuleVertexEdgeOrder ValidationRule = "vertex-edge-order"
+	// a vertex coordinate is NaN or infinite
+	RuleVertexCoords ValidationRule = "vertex-coords"
+	// an edge joins two vertices at the same position
+	RuleEdgeZeroLength ValidationRule = "edge-zero-length"
+	// a face normal does not point away from the origin
+	RuleFaceOrientation ValidationRule = "face-orientation"
+)
+
+// options for WingedGrid.Validate, the zero value runs all checks that apply
+// to any grid
+type ValidateOptions struct {
+	// skip the checks on vertex coordinates
+	SkipGeometry bool
+	// skip only the check that edges join vertices at different positions,
+	// which rounding coordinates to lower precision can break
+	AllowZeroLengthEdges bool
+	// check that each face normal points away from the origin, only
+	// meaningful for grids that approximate a sphere about the origin
+	Spherical bool
+	// stop after this many violations, zero for no limit
+	MaxViolations int
+}
+
+// a single broken invariant
+type Violation struct {
+	Element GridElement
+	Index   int32
+	Rule    ValidationRule
+	Message string
+}
+
+func (theViolation Violation) String() string {
+	return fmt.Sprintf("%s %d: %s: %s", theViolation.Element, theViolation.Index, theViolation.Rule, theViolation.Message)
+}
+
+// the result of WingedGrid.Validate
+type ValidationReport struct {
+	Violations []Violation
+	// set if validation stopped early at ValidateOptions.MaxViolations
+	Truncated bool
+}
+
+// Returns whether no violations were found
+func (report ValidationReport) Valid() bool {
+	return len(report.Violations) == 0
+}
+
+// Returns nil for a valid grid, otherwise an error describing the first
+// violation and the number found
+func (report ValidationReport) Err() error {
+	if report.Valid() {
+		return nil
+	}
+	if len(report.Violations) == 1 {
+		return errors.New("Invalid grid: " + report.Violations[0].String())
+	}
+	var more string
+	if report.Truncated {
+		more = " or more"
+	}
+	return fmt.Errorf("Invalid grid: %s (%d%s violations)", report.Violations[0], len(report.Violations), more)
+}
+
+// collects violations, stopping at the configured limit
+type validator struct {
+	grid    WingedGrid
+	options ValidateOptions
+	report  ValidationReport
+}
+
+func (v *validator) add(element GridElement, index int32, rule ValidationRule, format string, args ...interface{}) {
+	if v.full() {
+		v.report.Truncated = true
+		return
+	}
+	v.report.Violations = append(v.report.Violations, Violation{
+		Element: element,
+		Index:   index,
+		Rule:    rule,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+func (v *validator) full() bool {
+	return v.options.MaxViolations > 0 && len(v.report.Violations) >= v.options.MaxViolations
+}
+
+// Checks every topological invariant of the grid, and unless disabled the
+// geometric ones, returning a report of every violation found.
+func (theGrid WingedGrid) Validate(options ValidateOptions) ValidationReport {
+	var v validator = validator{grid: theGrid, options: options}
+
+	// ranges first, the remaining checks skip any element with a bad index
+	var edgesInRange []bool = v.checkEdgeRanges()
+	var facesInRange []bool = v.checkFaceRanges()
+	var verticesInRange []bool = v.checkVertexRanges()
+
+	for index := range theGrid.Edges {
+		if edgesInRange[index] {
+			v.checkEdge(int32(index))
+		}
+	}
+	for index := range theGrid.Faces {
+		if facesInRange[index] {
+			v.checkFace(int32(index), edgesInRange)
+		}
+	}
+	for index := range theGrid.Vertices {
+		if verticesInRange[index] {
+			v.checkVertex(int32(index), edgesInRange)
+		}
+	}
+
+	if !options.SkipGeometry {
+		v.checkGeometry(edgesInRange, facesInRange)
+	}
+
+	return v.report
+}
+
+/******************* Index Ranges ********************/
+
+func indexInRange(index int32, length int) bool {
+	return index >= 0 && index < int32(length)
+}
+
+func (v *validator) checkEdgeRanges() []bool {
+	var inRange []bool = make([]bool, len(v.grid.Edges))
+	var faceCount, edgeCount, vertexCount int = len(v.grid.Faces), len(v.grid.Edges), len(v.grid.Vertices)
+	for index, edge := range v.grid.Edges {
+		var edgeIndex int32 = int32(index)
+		inRange[index] = true
+		var check = func(name string, value int32, length int) {
+			if !indexInRange(value, length) {
+				v.add(ElementEdge, edgeIndex, RuleIndexRange, "%s %d out of range [0, %d)", name, value, length)
+				inRange[index] = false
+			}
+		}
+		check("FirstVertexA", edge.FirstVertexA, vertexCount)
+		check("FirstVertexB", edge.FirstVertexB, vertexCount)
+		if edge.FaceA == NoFace {
+			v.add(ElementEdge, edgeIndex, RuleEdgeBoundary, "FaceA is missing, a boundary edge keeps its face as FaceA")
+			inRange[index] = false
+		} else {
+			check("FaceA", edge.FaceA, faceCount)
+		}
+		check("PrevA", edge.PrevA, edgeCount)
+		check("NextA", edge.NextA, edgeCount)
+		if edge.FaceB == NoFace {
+			if edge.PrevB != NoEdge || edge.NextB != NoEdge {
+				v.add(ElementEdge, edgeIndex, RuleEdgeBoundary, "PrevB %d and NextB %d should be NoEdge with FaceB missing", edge.PrevB, edge.NextB)
+				inRange[index] = false
+			}
+		} else {
+			check("FaceB", edge.FaceB, faceCount)
+			check("PrevB", edge.PrevB, edgeCount)
+			check("NextB", edge.NextB, edgeCount)
+		}
+	}
+	return inRange
+}
+
+func (v *validator) checkFaceRanges() []bool {
+	var inRange []bool = make([]bool, len(v.grid.Faces))
+	for index, face := range v.grid.Faces {
+		inRange[index] = true
+		for i, edgeIndex := range face.Edges {
+			if !indexInRange(edgeIndex, len(v.grid.Edges)) {
+				v.add(ElementFace, int32(index), RuleIndexRange, "edge %d at position %d out of range [0, %d)", edgeIndex, i, len(v.grid.Edges))
+				inRange[index] = false
+			}
+		}
+	}
+	return inRange
+}
+
+func (v *validator) checkVertexRanges() []bool {
+	var inRange []bool = make([]bool, len(v.grid.Vertices))
+	for index, vertex := range v.grid.Vertices {
+		inRange[index] = true
+		for i, edgeIndex := range vertex.Edges {
+			if !indexInRange(edgeIndex, len(v.grid.Edges)) {
+				v.add(ElementVertex, int32(index), RuleIndexRange, "edge %d at position %d out of range [0, %d)", edgeIndex, i, len(v.grid.Edges))
+				inRange[index] = false
+			}
+		}
+	}
+	return inRange
+}
+
+/******************* Topology ********************/
+
+// edge must have all indices in range
+func (v *validator) checkEdge(edgeIndex int32) {
+	var theEdge WingedEdge = v.grid.Edges[edgeIndex]
+	if theEdge.FirstVertexA == theEdge.FirstVertexB {
+		v.add(ElementEdge, edgeIndex, RuleEdgeDegenerate, "both vertices are %d", theEdge.FirstVertexA)
+	}
+	if theEdge.FaceA == theEdge.FaceB {
+		v.add(ElementEdge, edgeIndex, RuleEdgeDegenerate, "both faces are %d", theEdge.FaceA)
+		// prev and next lookups are ambiguous with a single face
+		return
+	}
+
+	// the neighbors must point back at this edge for the same face
+	var sides = [2]struct {
+		name             string
+		face, prev, next int32
+	}{
+		{"A", theEdge.FaceA, theEdge.PrevA, theEdge.NextA},
+		{"B", theEdge.FaceB, theEdge.PrevB, theEdge.NextB},
+	}
+	for _, side := range sides {
+		if side.face == NoFace {
+			continue
+		}
+		back, err := v.grid.Edges[side.prev].NextEdgeForFace(side.face)
+		if err != nil || back != edgeIndex {
+			v.add(ElementEdge, edgeIndex, RuleEdgePrevNext, "Prev%s %d does not lead back for face %d", side.name, side.prev, side.face)
+		}
+		back, err = v.grid.Edges[side.next].PrevEdgeForFace(side.face)
+		if err != nil || back != edgeIndex {
+			v.add(ElementEdge, edgeIndex, RuleEdgePrevNext, "Next%s %d does not lead back for face %d", side.name, side.next, side.face)
+			continue
+		}
+		// the second vertex of this edge must start the next edge
+		second, _ := theEdge.SecondVertexForFace(side.face)
+		first, err := v.grid.Edges[side.next].FirstVertexForFace(side.face)
+		if err == nil && first != second {
+			v.add(ElementEdge, edgeIndex, RuleEdgeOrientation, "ends at vertex %d but Next%s %d starts at %d for face %d", second, side.name, side.next, first, side.face)
+		}
+	}
+}
+
+// face must have all edge indices in range
+func (v *validator) checkFace(faceIndex int32, edgesInRange []bool) {
+	var theFace WingedFace = v.grid.Faces[faceIndex]
+	if len(theFace.Edges) < 3 {
+		v.add(ElementFace, faceIndex, RuleFaceTooFewEdges, "has %d edges", len(theFace.Edges))
+		return
+	}
+	var linked bool = true
+	for i, edgeIndex := range theFace.Edges {
+		for _, otherIndex := range theFace.Edges[:i] {
+			if otherIndex == edgeIndex {
+				v.add(ElementFace, faceIndex, RuleFaceDuplicateEdge, "edge %d listed more than once", edgeIndex)
+			}
+		}
+		var theEdge WingedEdge = v.grid.Edges[edgeIndex]
+		if theEdge.FaceA != faceIndex && theEdge.FaceB != faceIndex {
+			v.add(ElementFace, faceIndex, RuleFaceEdgeNotLinked, "edge %d does not reference the face", edgeIndex)
+			linked = false
+		}
+	}
+	if !linked {
+		return
+	}
+	// edge order should follow next
+	for i, edgeIndex := range theFace.Edges {
+		if !edgesInRange[edgeIndex] {
+			return
+		}
+		next, _ := v.grid.Edges[edgeIndex].NextEdgeForFace(faceIndex)
+		var expected int32 = theFace.Edges[(i+1)%len(theFace.Edges)]
+		if next != expected {
+			v.add(ElementFace, faceIndex, RuleFaceEdgeOrder, "edge %d is followed by %d but the edge gives next as %d", edgeIndex, expected, next)
+			return
+		}
+	}
+}
+
+// vertex must have all edge indices in range
+func (v *validator) checkVertex(vertexIndex int32, edgesInRange []bool) {
+	var theVertex WingedVertex = v.grid.Vertices[vertexIndex]
+	if len(theVertex.Edges) == 0 {
+		v.add(ElementVertex, vertexIndex, RuleVertexNoEdges, "vertex has no edges")
+		return
+	}
+	for _, edgeIndex := range theVertex.Edges {
+		var theEdge WingedEdge = v.grid.Edges[edgeIndex]
+		if theEdge.FirstVertexA != vertexIndex && theEdge.FirstVertexB != vertexIndex {
+			v.add(ElementVertex, vertexIndex, RuleVertexEdgeNotLinked, "edge %d does not reference the vertex", edgeIndex)
+			return
+		}
+	}
+	for _, edgeIndex := range theVertex.Edges {
+		if !edgesInRange[edgeIndex] {
+			return
+		}
+	}
+	// edge order should follow the clockwise walk around the vertex, which
+	// on the boundary is an open fan from an edge with no previous edge
+	var last int32 = theVertex.Edges[len(theVertex.Edges)-1]
+	if next, _ := v.grid.Edges[last].NextEdgeForVertex(vertexIndex); next == NoEdge {
+		var first int32 = theVertex.Edges[0]
+		if prev, _ := v.grid.Edges[first].PrevEdgeForVertex(vertexIndex); prev != NoEdge {
+			v.add(ElementVertex, vertexIndex, RuleVertexEdgeOrder, "fan starts at edge %d but the edges give %d before it", first, prev)
+			return
+		}
+	}
+	for i, edgeIndex := range theVertex.Edges {
+		next, _ := v.grid.Edges[edgeIndex].NextEdgeForVertex(vertexIndex)
+		var expected int32 = theVertex.Edges[(i+1)%len(theVertex.Edges)]
+		if i == len(theVertex.Edges)-1 && next == NoEdge {
+			break
+		}
+		if next != expected {
+			v.add(ElementVertex, vertexIndex, RuleVertexEdgeOrder, "edge %d is followed by %d but the edges give next as %d", edgeIndex, expected, next)
+			return
+		}
+	}
+}
+
+/******************* Geometry ********************/
+
+func (v *validator) checkGeometry(edgesInRange, facesInRange []bool) {
+	for index, vertex := range v.grid.Vertices {
+		for _, value := range vertex.Coords {
+			if math.IsNaN(value) || math.IsInf(value, 0) {
+				v.add(ElementVertex, int32(index), RuleVertexCoords, "coordinates %v are not finite", vertex.Coords)
+				break
+			}
+		}
+	}
+	for index, edge := range v.grid.Edges {
+		if v.options.AllowZeroLengthEdges {
+			break
+		}
+		if !edgesInRange[index] || edge.FirstVertexA == edge.FirstVertexB {
+			continue
+		}
+		if v.grid.Vertices[edge.FirstVertexA].Coords == v.grid.Vertices[edge.FirstVertexB].Coords {
+			v.add(ElementEdge, int32(index), RuleEdgeZeroLength, "vertices %d and %d share a position", edge.FirstVertexA, edge.FirstVertexB)
+		}
+	}
+	if !v.options.Spherical {
+		return
+	}
+	for index := range v.grid.Faces {
+		if !facesInRange[index] {
+			continue
+		}
+		normal, center, ok := v.grid.faceNormalAndCenter(int32(index))
+		if !ok {
+			continue
+		}
+		if normal[0]*center[0]+normal[1]*center[1]+normal[2]*center[2] <= 0 {
+			v.add(ElementFace, int32(index), RuleFaceOrientation, "normal %v points toward the origin", normal)
+		}
+	}
+}
+
+// Returns the area weighted normal of a face, from the sum of the cross
+// products of its clockwise vertex pairs, along with the mean of its vertices.
+// Returns false if a vertex of the face can't be found.
+func (theGrid WingedGrid) faceNormalAndCenter(faceIndex int32) ([3]float64, [3]float64, bool) {
+	var normal, center [3]float64
+	var theFace WingedFace = theGrid.Faces[faceIndex]
+	if len(theFace.Edges) == 0 {
+		return normal, center, false
+	}
+	var coords [][3]float64 = make([][3]float64, len(theFace.Edges))
+	for i, edgeIndex := range theFace.Edges {
+		if !indexInRange(edgeIndex, len(theGrid.Edges)) {
+			return normal, center, false
+		}
+		vertexIndex, err := theGrid.Edges[edgeIndex].FirstVertexForFace(faceIndex)
+		if err != nil || !indexInRange(vertexIndex, len(theGrid.Vertices)) {
+			return normal, center, false
+		}
+		coords[i] = theGrid.Vertices[vertexIndex].Coords
+		center[0] += coords[i][0]
+		center[1] += coords[i][1]
+		center[2] += coords[i][2]
+	}
+	for i, current := range coords {
+		var next [3]float64 = coords[(i+1)%len(coords)]
+		normal[0] += current[1]*next[2] - current[2]*next[1]
+		normal[1] += current[2]*next[0] - current[0]*next[2]
+		normal[2] += current[0]*next[1] - current[1]*next[0]
+	}
+	var count float64 = float64(len(coords))
+	center[0] = center[0] / count
+	center[1] = center[1] / count
+	center[2] = center[2] / count
+	return normal, center, true
+}
@@ -0,0 +1,142 @@
+package wingedGrid
+
+import (
+	"math"
+	"testing"
+)
+
+func TestValidateGeneratedGrids(t *testing.T) {
+	base, err := BaseIcosahedron()
+	if err != nil {
+		t.Fatalf("Failed to create base icosahedron: %s", err)
+	}
+	subdivided, err := base.SubdivideTriangles(4)
+	if err != nil {
+		t.Fatalf("Failed to subdivide base icosahedron: %s", err)
+	}
+	dual, err := subdivided.CreateDual()
+	if err != nil {
+		t.Fatalf("Error creating dual: %s", err)
+	}
+	for name, grid := range map[string]WingedGrid{"icosahedron": base, "subdivided": subdivided, "dual": dual} {
+		report := grid.Validate(ValidateOptions{Spherical: true})
+		if !report.Valid() {
+			t.Errorf("Expected %s to be valid, got: %s", name, report.Err())
+		}
+	}
+}
+
+func TestValidateReportsIndexRange(t *testing.T) {
+	grid, _ := BaseIcosahedron()
+	grid.Edges[3].NextB = 400
+	grid.Faces[7].Edges[1] = -2
+	grid.Vertices[5].Edges[0] = 30
+
+	report := grid.Validate(ValidateOptions{})
+	if report.Valid() {
+		t.Fatal("Expected out of range indices to be reported")
+	}
+	expected := map[GridElement]int32{ElementEdge: 3, ElementFace: 7, ElementVertex: 5}
+	for _, violation := range report.Violations {
+		if violation.Rule != RuleIndexRange {
+			continue
+		}
+		if index, ok := expected[violation.Element]; ok && index == violation.Index {
+			delete(expected, violation.Element)
+		}
+	}
+	if len(expected) != 0 {
+		t.Errorf("Missing index range violations for %v, got: %v", expected, report.Violations)
+	}
+}
+
+func TestValidateReportsBrokenLinks(t *testing.T) {
+	grid, _ := BaseIcosahedron()
+	// swap the vertices of an edge, breaking its orientation
+	grid.Edges[0].FirstVertexA, grid.Edges[0].FirstVertexB = grid.Edges[0].FirstVertexB, grid.Edges[0].FirstVertexA
+	// reverse a face edge list
+	grid.Faces[12].Edges[0], grid.Faces[12].Edges[2] = grid.Faces[12].Edges[2], grid.Faces[12].Edges[0]
+
+	report := grid.Validate(ValidateOptions{})
+	var foundOrientation, foundFaceOrder bool
+	for _, violation := range report.Violations {
+		if violation.Rule == RuleEdgeOrientation && violation.Element == ElementEdge && violation.Index == 0 {
+			foundOrientation = true
+		}
+		if violation.Rule == RuleFaceEdgeOrder && violation.Element == ElementFace && violation.Index == 12 {
+			foundFaceOrder = true
+		}
+	}
+	if !foundOrientation {
+		t.Errorf("Expected an orientation violation for edge 0, got: %v", report.Violations)
+	}
+	if !foundFaceOrder {
+		t.Errorf("Expected an edge order violation for face 12, got: %v", report.Violations)
+	}
+}
+
+func TestValidateGeometry(t *testing.T) {
+	grid, _ := BaseIcosahedron()
+	grid.Vertices[2].Coords[1] = math.NaN()
+	report := grid.Validate(ValidateOptions{})
+	if report.Valid() || report.Violations[0].Rule != RuleVertexCoords {
+		t.Errorf("Expected a coordinate violation, got: %v", report.Violations)
+	}
+	if !grid.Validate(ValidateOptions{SkipGeometry: true}).Valid() {
+		t.Error("Expected geometry checks to be skipped")
+	}
+	if grid.Validate(ValidateOptions{AllowZeroLengthEdges: true}).Valid() {
+		t.Error("Expected allowing zero length edges to keep the coordinate check")
+	}
+	grid, _ = BaseIcosahedron()
+	grid.Vertices[grid.Edges[0].FirstVertexB].Coords = grid.Vertices[grid.Edges[0].FirstVertexA].Coords
+	report = grid.Validate(ValidateOptions{})
+	if report.Valid() || report.Violations[0].Rule != RuleEdgeZeroLength {
+		t.Errorf("Expected a zero length edge violation, got: %v", report.Violations)
+	}
+	if !grid.Validate(ValidateOptions{AllowZeroLengthEdges: true}).Valid() {
+		t.Error("Expected zero length edges to be allowed")
+	}
+
+	// mirroring the sphere turns every face inside out
+	grid, _ = BaseIcosahedron()
+	for i := range grid.Vertices {
+		grid.Vertices[i].Coords[0] = -grid.Vertices[i].Coords[0]
+	}
+	report = grid.Validate(ValidateOptions{Spherical: true, MaxViolations: 5})
+	if len(report.Violations) != 5 || !report.Truncated {
+		t.Errorf("Expected 5 violations and a truncated report, got %d", len(report.Violations))
+	}
+	for _, violation := range report.Violations {
+		if violation.Rule != RuleFaceOrientation {
+			t.Errorf("Unexpected violation: %s", violation)
+		}
+	}
+}
+
+func TestValidateReportsBoundaryEdges(t *testing.T) {
+	base, _ := BaseIcosahedron()
+	var boundaryEdge int32 = -1
+	patch := northernPatch(t, base)
+	for index, edge := range patch.Edges {
+		if edge.IsBoundary() {
+			boundaryEdge = int32(index)
+			break
+		}
+	}
+	if boundaryEdge < 0 {
+		t.Fatal("Expected a boundary edge")
+	}
+	patch.Edges[boundaryEdge].NextB = patch.Edges[boundaryEdge].NextA
+
+	report := patch.Validate(ValidateOptions{})
+	var found bool
+	for _, violation := range report.Violations {
+		if violation.Rule == RuleEdgeBoundary && violation.Element == ElementEdge && violation.Index == boundaryEdge {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("Expected a boundary violation for edge %d, got: %v", boundaryEdge, report.Violations)
+	}
+}
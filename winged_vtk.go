@@ -0,0 +1,214 @@
+package wingedGrid
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// VTK unstructured grid export, as legacy ASCII .vtk or XML .vtu files, for
+// ParaView and other scientific visualization tools.
+//
+// Each face becomes a cell: triangles as VTK_TRIANGLE, quads as VTK_QUAD and
+// anything larger as VTK_POLYGON, with points in clockwise face order.
+
+const (
+	vtkTriangle = 5
+	vtkPolygon  = 7
+	vtkQuad     = 9
+)
+
+// a named array of values per point or per cell
+type VTKArray struct {
+	// may not contain whitespace
+	Name string
+	// 1 for scalars, 3 for vectors
+	Components int
+	// Components values for each point or cell, in index order
+	Values []float64
+}
+
+// options for WingedGrid.WriteVTK and WingedGrid.WriteVTU
+type VTKOptions struct {
+	// one entry per vertex
+	PointData []VTKArray
+	// one entry per face
+	CellData []VTKArray
+}
+
+// Writes the grid as a legacy ASCII VTK unstructured grid
+func (theGrid WingedGrid) WriteVTK(w io.Writer, options VTKOptions) error {
+	faceVertices, err := theGrid.checkVTK(options)
+	if err != nil {
+		return err
+	}
+	var out *bufio.Writer = bufio.NewWriter(w)
+	out.WriteString("# vtk DataFile Version 3.0\nWingedGrid\nASCII\nDATASET UNSTRUCTURED_GRID\n")
+	fmt.Fprintf(out, "POINTS %d double\n", len(theGrid.Vertices))
+	for _, vertex := range theGrid.Vertices {
+		writeVTKValues(out, vertex.Coords[:])
+		out.WriteByte('\n')
+	}
+
+	var cellSize int
+	for _, vertices := range faceVertices {
+		cellSize += len(vertices) + 1
+	}
+	fmt.Fprintf(out, "CELLS %d %d\n", len(faceVertices), cellSize)
+	for _, vertices := range faceVertices {
+		out.WriteString(strconv.Itoa(len(vertices)))
+		for _, vertexIndex := range vertices {
+			out.WriteByte(' ')
+			out.WriteString(strconv.Itoa(int(vertexIndex)))
+		}
+		out.WriteByte('\n')
+	}
+	fmt.Fprintf(out, "CELL_TYPES %d\n", len(faceVertices))
+	for _, vertices := range faceVertices {
+		fmt.Fprintf(out, "%d\n", vtkCellType(len(vertices)))
+	}
+
+	writeVTKLegacyArrays(out, "POINT_DATA", len(theGrid.Vertices), options.PointData)
+	writeVTKLegacyArrays(out, "CELL_DATA", len(theGrid.Faces), options.CellData)
+	return out.Flush()
+}
+
+// Writes the grid as an ASCII XML VTK unstructured grid
+func (theGrid WingedGrid) WriteVTU(w io.Writer, options VTKOptions) error {
+	faceVertices, err := theGrid.checkVTK(options)
+	if err != nil {
+		return err
+	}
+	var out *bufio.Writer = bufio.NewWriter(w)
+	out.WriteString("<?xml version=\"1.0\"?>\n")
+	out.WriteString("<VTKFile type=\"UnstructuredGrid\" version=\"0.1\" byte_order=\"LittleEndian\">\n<UnstructuredGrid>\n")
+	fmt.Fprintf(out, "<Piece NumberOfPoints=\"%d\" NumberOfCells=\"%d\">\n", len(theGrid.Vertices), len(theGrid.Faces))
+
+	writeVTUArrays(out, "PointData", options.PointData)
+	writeVTUArrays(out, "CellData", options.CellData)
+
+	out.WriteString("<Points>\n<DataArray type=\"Float64\" NumberOfComponents=\"3\" format=\"ascii\">\n")
+	for _, vertex := range theGrid.Vertices {
+		writeVTKValues(out, vertex.Coords[:])
+		out.WriteByte('\n')
+	}
+	out.WriteString("</DataArray>\n</Points>\n<Cells>\n")
+
+	out.WriteString("<DataArray type=\"Int32\" Name=\"connectivity\" format=\"ascii\">\n")
+	for _, vertices := range faceVertices {
+		for i, vertexIndex := range vertices {
+			if i > 0 {
+				out.WriteByte(' ')
+			}
+			out.WriteString(strconv.Itoa(int(vertexIndex)))
+		}
+		out.WriteByte('\n')
+	}
+	out.WriteString("</DataArray>\n<DataArray type=\"Int32\" Name=\"offsets\" format=\"ascii\">\n")
+	var offset int
+	for _, vertices := range faceVertices {
+		offset += len(vertices)
+		fmt.Fprintf(out, "%d\n", offset)
+	}
+	out.WriteString("</DataArray>\n<DataArray type=\"UInt8\" Name=\"types\" format=\"ascii\">\n")
+	for _, vertices := range faceVertices {
+		fmt.Fprintf(out, "%d\n", vtkCellType(len(vertices)))
+	}
+	out.WriteString("</DataArray>\n</Cells>\n</Piece>\n</UnstructuredGrid>\n</VTKFile>\n")
+	return out.Flush()
+}
+
+// Returns the face vertex lists, or an error if an array doesn't fit the grid
+func (theGrid WingedGrid) checkVTK(options VTKOptions) ([][]int32, error) {
+	err := checkVTKArrays("point", len(theGrid.Vertices), options.PointData)
+	if err != nil {
+		return nil, err
+	}
+	err = checkVTKArrays("cell", len(theGrid.Faces), options.CellData)
+	if err != nil {
+		return nil, err
+	}
+	faceVertices, err := theGrid.faceVertexLists()
+	if err != nil {
+		return nil, err
+	}
+	return faceVertices, theGrid.checkNoSeamFaces(faceVertices)
+}
+
+func checkVTKArrays(element string, count int, arrays []VTKArray) error {
+	var names map[string]bool = make(map[string]bool)
+	for _, array := range arrays {
+		if array.Name == "" || strings.ContainsAny(array.Name, " \t\r\n") {
+			return fmt.Errorf("Invalid %s array name %q", element, array.Name)
+		}
+		if names[array.Name] {
+			return fmt.Errorf("Duplicate %s array name %q", element, array.Name)
+		}
+		names[array.Name] = true
+		if array.Components != 1 && array.Components != 3 {
+			return fmt.Errorf("%s array %q has %d components, expected 1 or 3", element, array.Name, array.Components)
+		}
+		if len(array.Values) != count*array.Components {
+			return fmt.Errorf("%s array %q has %d values, expected %d", element, array.Name, len(array.Values), count*array.Components)
+		}
+	}
+	return nil
+}
+
+func vtkCellType(vertexCount int) int {
+	switch vertexCount {
+	case 3:
+		return vtkTriangle
+	case 4:
+		return vtkQuad
+	}
+	return vtkPolygon
+}
+
+func writeVTKValues(out *bufio.Writer, values []float64) {
+	for i, value := range values {
+		if i > 0 {
+			out.WriteByte(' ')
+		}
+		out.WriteString(strconv.FormatFloat(value, 'g', -1, 64))
+	}
+}
+
+func writeVTKLegacyArrays(out *bufio.Writer, section string, count int, arrays []VTKArray) {
+	if len(arrays) == 0 {
+		return
+	}
+	fmt.Fprintf(out, "%s %d\n", section, count)
+	for _, array := range arrays {
+		if array.Components == 1 {
+			fmt.Fprintf(out, "SCALARS %s double 1\nLOOKUP_TABLE default\n", array.Name)
+		} else {
+			fmt.Fprintf(out, "VECTORS %s double\n", array.Name)
+		}
+		for i := 0; i < count; i++ {
+			writeVTKValues(out, array.Values[i*array.Components:(i+1)*array.Components])
+			out.WriteByte('\n')
+		}
+	}
+}
+
+func writeVTUArrays(out *bufio.Writer, section string, arrays []VTKArray) {
+	if len(arrays) == 0 {
+		return
+	}
+	fmt.Fprintf(out, "<%s>\n", section)
+	for _, array := range arrays {
+		out.WriteString("<DataArray type=\"Float64\" Name=\"")
+		xml.EscapeText(out, []byte(array.Name))
+		fmt.Fprintf(out, "\" NumberOfComponents=\"%d\" format=\"ascii\">\n", array.Components)
+		for i := 0; i < len(array.Values); i += array.Components {
+			writeVTKValues(out, array.Values[i:i+array.Components])
+			out.WriteByte('\n')
+		}
+		out.WriteString("</DataArray>\n")
+	}
+	fmt.Fprintf(out, "</%s>\n", section)
+}
@@ -0,0 +1,133 @@
+package wingedGrid
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+)
+
+// a dual grid of pentagons and hexagons with one array of each kind
+func vtkTestGrid() (WingedGrid, VTKOptions) {
+	base, _ := BaseIcosahedron()
+	grid, _ := base.SubdivideTriangles(1)
+	dual, _ := grid.CreateDual()
+	temperature := make([]float64, len(dual.Faces))
+	for i := range temperature {
+		temperature[i] = float64(i) + 0.5
+	}
+	wind := make([]float64, 3*len(dual.Vertices))
+	for i := range wind {
+		wind[i] = float64(i % 3)
+	}
+	return dual, VTKOptions{
+		PointData: []VTKArray{{Name: "wind", Components: 3, Values: wind}},
+		CellData:  []VTKArray{{Name: "temperature", Components: 1, Values: temperature}},
+	}
+}
+
+func TestVTKLegacy(t *testing.T) {
+	dual, options := vtkTestGrid()
+	var buffer bytes.Buffer
+	if err := dual.WriteVTK(&buffer, options); err != nil {
+		t.Fatalf("Failed to write VTK: %s", err)
+	}
+	text := buffer.String()
+	// 12 pentagons and 30 hexagons
+	for _, expected := range []string{
+		"POINTS 80 double\n",
+		"CELLS 42 282\n",
+		"CELL_TYPES 42\n",
+		"POINT_DATA 80\nVECTORS wind double\n0 1 2\n",
+		"CELL_DATA 42\nSCALARS temperature double 1\nLOOKUP_TABLE default\n0.5\n",
+	} {
+		if !strings.Contains(text, expected) {
+			t.Errorf("Missing %q", expected)
+		}
+	}
+	types := strings.SplitN(strings.SplitN(text, "CELL_TYPES 42\n", 2)[1], "POINT_DATA", 2)[0]
+	if strings.Count(types, "7\n") != 42 {
+		t.Errorf("Expected 42 polygon cells, got:\n%s", types)
+	}
+}
+
+type vtuTestFile struct {
+	Piece struct {
+		NumberOfPoints int `xml:",attr"`
+		NumberOfCells  int `xml:",attr"`
+		PointData      struct {
+			DataArray []vtuTestArray
+		}
+		CellData struct {
+			DataArray []vtuTestArray
+		}
+		Cells struct {
+			DataArray []vtuTestArray
+		}
+	} `xml:"UnstructuredGrid>Piece"`
+}
+
+type vtuTestArray struct {
+	Name               string `xml:",attr"`
+	NumberOfComponents int    `xml:",attr"`
+	Values             string `xml:",chardata"`
+}
+
+func TestVTU(t *testing.T) {
+	base, _ := BaseIcosahedron()
+	_, options := vtkTestGrid()
+	// arrays sized for the dual don't fit the icosahedron
+	var buffer bytes.Buffer
+	if err := base.WriteVTU(&buffer, options); err == nil {
+		t.Fatal("Expected an error for arrays of the wrong size")
+	}
+	dual, options := vtkTestGrid()
+	buffer.Reset()
+	if err := dual.WriteVTU(&buffer, options); err != nil {
+		t.Fatalf("Failed to write VTU: %s", err)
+	}
+	var file vtuTestFile
+	if err := xml.Unmarshal(buffer.Bytes(), &file); err != nil {
+		t.Fatalf("Invalid XML: %s", err)
+	}
+	if file.Piece.NumberOfPoints != 80 || file.Piece.NumberOfCells != 42 {
+		t.Fatalf("Unexpected counts %d %d", file.Piece.NumberOfPoints, file.Piece.NumberOfCells)
+	}
+	if len(file.Piece.PointData.DataArray) != 1 || file.Piece.PointData.DataArray[0].NumberOfComponents != 3 {
+		t.Errorf("Unexpected point data %+v", file.Piece.PointData)
+	}
+	if len(file.Piece.CellData.DataArray) != 1 || len(strings.Fields(file.Piece.CellData.DataArray[0].Values)) != 42 {
+		t.Errorf("Unexpected cell data %+v", file.Piece.CellData)
+	}
+	cells := file.Piece.Cells.DataArray
+	if len(cells) != 3 || len(strings.Fields(cells[0].Values)) != 12*5+30*6 {
+		t.Fatalf("Unexpected cells %+v", cells)
+	}
+	offsets := strings.Fields(cells[1].Values)
+	if offsets[len(offsets)-1] != "240" {
+		t.Errorf("Expected last offset 240, got %s", offsets[len(offsets)-1])
+	}
+}
+
+func TestVTKRejectsBadArrays(t *testing.T) {
+	grid, _ := BaseIcosahedron()
+	var buffer bytes.Buffer
+	bad := []VTKOptions{
+		{PointData: []VTKArray{{Name: "a", Components: 2, Values: make([]float64, 24)}}},
+		{PointData: []VTKArray{{Name: "a b", Components: 1, Values: make([]float64, 12)}}},
+		{CellData: []VTKArray{{Name: "a", Components: 1, Values: make([]float64, 20)}, {Name: "a", Components: 1, Values: make([]float64, 20)}}},
+	}
+	for _, options := range bad {
+		if err := grid.WriteVTK(&buffer, options); err == nil {
+			t.Errorf("Expected an error for options %+v", options)
+		}
+	}
+	buffer.Reset()
+	if err := grid.WriteVTK(&buffer, VTKOptions{}); err != nil {
+		t.Fatalf("Failed to write VTK: %s", err)
+	}
+	types := strings.Fields(strings.SplitN(buffer.String(), "CELL_TYPES 20\n", 2)[1])
+	if len(types) != 20 || strings.Join(types, "") != strings.Repeat("5", 20) {
+		t.Errorf("Expected 20 triangle cells, got %v", types)
+	}
+}